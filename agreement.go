@@ -0,0 +1,63 @@
+package nlp
+
+import "strings"
+
+func numeralAgreement(n int64) (Case, Number) {
+	u := uint64(n)
+	if n < 0 {
+		u = -u
+	}
+
+	switch {
+	case u%100 >= 11 && u%100 <= 14:
+		return Gen, Plur
+	case u%10 == 1:
+		return Nom, Sing
+	case u%10 >= 2 && u%10 <= 4:
+		return Gen, Sing
+	}
+	return Gen, Plur
+}
+
+func (l *Lemmatizer) AgreeWithNumber(lemma string, n int64) string {
+	words := strings.Fields(Normalize(lemma))
+	if len(words) == 0 {
+		return ""
+	}
+
+	c, number := numeralAgreement(n)
+
+	head := words[len(words)-1]
+	var gender Gender
+	for _, f := range l.Lexeme(head) {
+		if f.FEATS.POS() == NOUN && f.FEATS.Gender() != 0 {
+			gender = f.FEATS.Gender()
+			break
+		}
+	}
+
+	nounTarget := FEATS(0).SetPOS(NOUN).SetCase(c).SetNumber(number)
+	if forms := l.Inflect(head, nounTarget); len(forms) > 0 {
+		words[len(words)-1] = forms[0]
+	} else if forms := l.Inflect(head, FEATS(0).SetCase(c).SetNumber(number)); len(forms) > 0 {
+		words[len(words)-1] = forms[0]
+	}
+
+	adjTarget := FEATS(0).SetPOS(ADJ)
+	switch {
+	case number == Sing && c == Nom:
+		adjTarget = adjTarget.SetCase(Nom).SetNumber(Sing).SetGender(gender)
+	case number == Sing && gender == Fem:
+		adjTarget = adjTarget.SetCase(Nom).SetNumber(Plur)
+	default:
+		adjTarget = adjTarget.SetCase(Gen).SetNumber(Plur)
+	}
+
+	for i, w := range words[:len(words)-1] {
+		if forms := l.Inflect(w, adjTarget); len(forms) > 0 {
+			words[i] = forms[0]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,202 @@
+package nlp
+
+import (
+	"math"
+	"sort"
+)
+
+type ParseSource uint8
+
+const (
+	SourceDictionary ParseSource = iota + 1
+	SourceSuffixPredictor
+	SourceUser
+)
+
+func (s ParseSource) String() string {
+	switch s {
+	case SourceDictionary:
+		return "dictionary"
+	case SourceSuffixPredictor:
+		return "suffix"
+	case SourceUser:
+		return "user"
+	}
+	return "unknown"
+}
+
+type Parse struct {
+	Lemma       string
+	FEATS       FEATS
+	CountTotal  uint16
+	CountDocs   uint16
+	Probability float64
+	Source      ParseSource
+	// Chain lists the dictionary links followed to reach Lemma.
+	Chain []LinkStep
+}
+
+func (l *Lemmatizer) Analyze(word string) []Parse {
+	word = Normalize(word)
+
+	var parses []Parse
+	total := 0.0
+
+	if forms := l.getForms(word); len(forms) > 0 {
+		parses = make([]Parse, 0, len(forms))
+		for _, f := range forms {
+			source := SourceDictionary
+			if f.LemmaIdx&userLemmaFlag != 0 {
+				source = SourceUser
+			}
+			weight := float64(f.CountTotal) + 1
+			total += weight
+			parses = append(parses, Parse{
+				Lemma:       l.FormLemma(f, word),
+				FEATS:       f.FEATS,
+				CountTotal:  f.CountTotal,
+				CountDocs:   f.CountDocs,
+				Probability: weight,
+				Source:      source,
+				Chain:       l.LinkChain(f),
+			})
+		}
+	} else {
+		predictions := l.base.SuffixPredictor.Predict(word)
+		type parseKey struct {
+			lemma string
+			tag   FEATS
+		}
+		seen := map[parseKey]struct{}{}
+		for _, pred := range predictions {
+			if pred.MatchLen < predictions[0].MatchLen-1 {
+				break
+			}
+
+			key := parseKey{lemma: pred.Lemma, tag: pred.Tag}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			weight := float64(pred.RuleCounter) + 1
+			total += weight
+			parses = append(parses, Parse{
+				Lemma:       pred.Lemma,
+				FEATS:       pred.Tag,
+				CountTotal:  uint16(min(pred.RuleCounter, math.MaxUint16)),
+				Probability: weight,
+				Source:      SourceSuffixPredictor,
+			})
+		}
+	}
+
+	for i := range parses {
+		parses[i].Probability /= total
+	}
+	sort.SliceStable(parses, func(i, j int) bool {
+		return parses[i].Probability > parses[j].Probability
+	})
+
+	return parses
+}
+
+type AnalyzedToken struct {
+	Text       string
+	Surface    string
+	Start      int
+	End        int
+	Type       TokenType
+	Lemma      string
+	FEATS      FEATS
+	POS        POS
+	Confidence float64
+}
+
+func (l *Lemmatizer) AnalyzeText(text string) []AnalyzedToken {
+	return l.AnalyzeTokens(Tokenize(text, l.keywords))
+}
+
+func (l *Lemmatizer) AnalyzeTokens(tokens []Token) []AnalyzedToken {
+	return l.dropStopwords(l.analyzeTokens(tokens, true))
+}
+
+// analyzeTokens keeps one result per token regardless of WithStopwords; without
+// confidence it skips forward-backward and leaves every Confidence at 1.
+func (l *Lemmatizer) analyzeTokens(tokens []Token, confidence bool) []AnalyzedToken {
+	return l.analyze(tokens, []Sentence{{Start: 0, End: len(tokens)}}, confidence)[0]
+}
+
+func (l *Lemmatizer) AnalyzeSentences(tokens []Token) [][]AnalyzedToken {
+	sentences := l.analyze(tokens, SplitSentences(tokens), true)
+	for i := range sentences {
+		sentences[i] = l.dropStopwords(sentences[i])
+	}
+	return sentences
+}
+
+func (l *Lemmatizer) dropStopwords(tokens []AnalyzedToken) []AnalyzedToken {
+	if l.stopwords == nil {
+		return tokens
+	}
+	return l.stopwords.Filter(tokens)
+}
+
+func (l *Lemmatizer) analyze(tokens []Token, sentences []Sentence, confidence bool) [][]AnalyzedToken {
+	result := make([]AnalyzedToken, len(tokens))
+	for i := range tokens {
+		t := &tokens[i]
+		pos := UNKNOWN
+		switch t.Type() {
+		case TokenPunct:
+			pos = PUNCT
+		case TokenSym:
+			pos = SYM
+		}
+
+		result[i] = AnalyzedToken{
+			Text:       t.Text(),
+			Surface:    t.Surface(),
+			Start:      t.Start(),
+			End:        t.End(),
+			Type:       t.Type(),
+			Lemma:      t.Text(),
+			FEATS:      FEATS(0).SetPOS(pos),
+			POS:        pos,
+			Confidence: 1,
+		}
+	}
+
+	grouped := make([][]AnalyzedToken, 0, len(sentences))
+	for _, s := range sentences {
+		words := l.Candidates(s.Tokens(tokens))
+		forms := l.Viterbi(words)
+		var marginals [][]float64
+		if confidence {
+			marginals = l.Marginals(words)
+		}
+		for i, w := range words {
+			form := forms[i]
+			res := &result[s.Start+w.TokenID]
+			res.Lemma = l.FormLemma(form, w.Text)
+			res.FEATS = form.FEATS
+			res.POS = form.FEATS.POS()
+			if confidence {
+				res.Confidence = tagMarginal(w.Options, marginals[i], form.FEATS)
+			}
+		}
+		grouped = append(grouped, result[s.Start:s.End:s.End])
+	}
+
+	return grouped
+}
+
+func tagMarginal(options []Form, marginals []float64, tag FEATS) float64 {
+	total := 0.0
+	for i, f := range options {
+		if f.FEATS&BigramMask == tag&BigramMask {
+			total += marginals[i]
+		}
+	}
+	return total
+}
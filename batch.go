@@ -0,0 +1,131 @@
+package nlp
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type BatchOptions struct {
+	// Workers is the number of goroutines; zero means GOMAXPROCS.
+	Workers int
+	// Buffer bounds how many texts LemmatizeStream keeps in flight; zero means 4 per worker.
+	Buffer int
+}
+
+func (o BatchOptions) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+func (o BatchOptions) buffer() int {
+	if o.Buffer > 0 {
+		return o.Buffer
+	}
+	return 4 * o.workers()
+}
+
+// LemmatizeBatch lemmatizes texts in parallel and returns the results in input
+// order. On cancellation it stops early and returns the context error.
+func (l *Lemmatizer) LemmatizeBatch(ctx context.Context, texts []string, opts BatchOptions) ([][]string, error) {
+	results := make([][]string, len(texts))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(opts.workers(), len(texts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= len(texts) {
+					return
+				}
+				results[i] = l.LemmatizeText(texts[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type batchJob struct {
+	text   string
+	result chan []string
+}
+
+// LemmatizeStream lemmatizes texts as they arrive and emits the results in
+// input order. The returned channel is closed once texts is closed and drained,
+// or early when ctx is cancelled; check ctx.Err() to tell the two apart.
+func (l *Lemmatizer) LemmatizeStream(ctx context.Context, texts <-chan string, opts BatchOptions) <-chan []string {
+	out := make(chan []string)
+	jobs := make(chan batchJob)
+	pending := make(chan chan []string, opts.buffer())
+
+	var wg sync.WaitGroup
+	for range opts.workers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.result <- l.LemmatizeText(job.text)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for {
+			var text string
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case text, ok = <-texts:
+				if !ok {
+					return
+				}
+			}
+
+			job := batchJob{text: text, result: make(chan []string, 1)}
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- job.result:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- job:
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		for result := range pending {
+			var lemmas []string
+			select {
+			case <-ctx.Done():
+				return
+			case lemmas = <-result:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- lemmas:
+			}
+		}
+	}()
+
+	return out
+}
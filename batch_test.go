@@ -0,0 +1,126 @@
+package nlp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchTexts(n int) []string {
+	samples := []string{
+		"Мама мыла раму.",
+		"В новом доме стали новый дом.",
+		"Он читает книги в Москве. Новый файл!",
+		"Быстрее читать новые файлы",
+		"",
+	}
+	texts := make([]string, n)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("%s %d", samples[i%len(samples)], i)
+	}
+	return texts
+}
+
+func TestLemmatizeBatch(t *testing.T) {
+	l := newTestLemmatizer(t)
+	texts := batchTexts(200)
+
+	results, err := l.LemmatizeBatch(context.Background(), texts, BatchOptions{Workers: 8})
+	require.NoError(t, err)
+	require.Len(t, results, len(texts))
+	for i, text := range texts {
+		assert.Equal(t, l.LemmatizeText(text), results[i])
+	}
+
+	results, err = l.LemmatizeBatch(context.Background(), nil, BatchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestLemmatizeBatchCancel(t *testing.T) {
+	l := newTestLemmatizer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := l.LemmatizeBatch(ctx, batchTexts(100), BatchOptions{Workers: 4})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, results)
+}
+
+func TestLemmatizeStream(t *testing.T) {
+	l := newTestLemmatizer(t)
+	texts := batchTexts(300)
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, text := range texts {
+			in <- text
+		}
+	}()
+
+	var results [][]string
+	for lemmas := range l.LemmatizeStream(context.Background(), in, BatchOptions{Workers: 6, Buffer: 3}) {
+		results = append(results, lemmas)
+	}
+
+	require.Len(t, results, len(texts))
+	for i, text := range texts {
+		assert.Equal(t, l.LemmatizeText(text), results[i])
+	}
+}
+
+func TestLemmatizeStreamCancel(t *testing.T) {
+	l := newTestLemmatizer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan string)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case in <- fmt.Sprint("мама мыла раму ", i):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := l.LemmatizeStream(ctx, in, BatchOptions{Workers: 4})
+	for range 10 {
+		<-out
+	}
+	cancel()
+
+	for range out {
+	}
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestLemmatizerConcurrentUse(t *testing.T) {
+	l := newTestLemmatizer(t)
+	texts := batchTexts(20)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, text := range texts {
+				tokens := Tokenize(text, l.keywords)
+				l.AnalyzeSentences(tokens)
+				words := l.Candidates(tokens)
+				l.Marginals(words)
+				l.NBest(words, 3)
+				l.Analyze("стали")
+				l.LemmatizeWord("абырвалг")
+				l.Inflect("дом", noun(0, Gen, Plur))
+				l.AgreeWithNumber("новый файл", int64(g*len(texts)+i))
+			}
+		}()
+	}
+	wg.Wait()
+}
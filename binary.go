@@ -0,0 +1,656 @@
+package nlp
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"unsafe"
+)
+
+// The binary format is a header followed by 8-byte aligned sections:
+//
+//	magic    [8]byte  "NLPDATA\x00"
+//	version  uint32
+//	count    uint32   number of sections
+//	table    count × {id uint32, crc uint32, offset uint64, length uint64}
+//	crc      uint32   CRC-32C of everything above
+//
+// All integers are little-endian and every section carries its own CRC-32C.
+// Record sections store fixed-size records laid out exactly as the Go structs
+// are on 64-bit little-endian platforms, so a mapped file is used in place;
+// elsewhere the records are decoded into fresh slices.
+
+var binaryMagic = [8]byte{'N', 'L', 'P', 'D', 'A', 'T', 'A', 0}
+
+const BinaryVersion = 1
+
+const (
+	binaryHeaderSize = 16
+	binaryEntrySize  = 24
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+const (
+	sectionMeta = iota + 1
+	sectionLinkTypes
+	sectionTexts
+	sectionFormTexts
+	sectionForms
+	sectionLemmas
+	sectionLinks
+	sectionFormIndex
+	sectionLemmaFormsStart
+	sectionLemmaForms
+	sectionTagTotals
+	sectionTransitions
+	sectionTagPairs
+	sectionTrigrams
+	sectionRules
+	sectionNodes
+	sectionEdges
+	sectionAppendTexts
+)
+
+type formIndexEntry struct {
+	Hash uint64
+	Idx  uint32
+}
+
+type codec[T comparable] struct {
+	size   int
+	encode func([]byte, T)
+	decode func([]byte) T
+	sample T
+}
+
+var le = binary.LittleEndian
+
+var (
+	uint32Codec = codec[uint32]{
+		size:   4,
+		encode: func(b []byte, v uint32) { le.PutUint32(b, v) },
+		decode: func(b []byte) uint32 { return le.Uint32(b) },
+		sample: 0x01020304,
+	}
+	formTextCodec = codec[FormText]{
+		size: 12,
+		encode: func(b []byte, v FormText) {
+			le.PutUint32(b, v.TextStart)
+			le.PutUint32(b[4:], v.FormIdx)
+			b[8], b[9] = v.TextLen, v.FormLen
+		},
+		decode: func(b []byte) FormText {
+			return FormText{TextStart: le.Uint32(b), FormIdx: le.Uint32(b[4:]), TextLen: b[8], FormLen: b[9]}
+		},
+		sample: FormText{TextStart: 1, FormIdx: 2, TextLen: 3, FormLen: 4},
+	}
+	formCodec = codec[Form]{
+		size: 12,
+		encode: func(b []byte, v Form) {
+			le.PutUint32(b, v.LemmaIdx)
+			le.PutUint32(b[4:], uint32(v.FEATS))
+			le.PutUint16(b[8:], v.CountTotal)
+			le.PutUint16(b[10:], v.CountDocs)
+		},
+		decode: func(b []byte) Form {
+			return Form{LemmaIdx: le.Uint32(b), FEATS: FEATS(le.Uint32(b[4:])), CountTotal: le.Uint16(b[8:]), CountDocs: le.Uint16(b[10:])}
+		},
+		sample: Form{LemmaIdx: 1, FEATS: 2, CountTotal: 3, CountDocs: 4},
+	}
+	lemmaCodec = codec[Lemma]{
+		size: 20,
+		encode: func(b []byte, v Lemma) {
+			le.PutUint32(b, v.TextStart)
+			le.PutUint32(b[4:], v.LinkIdx)
+			le.PutUint32(b[8:], uint32(v.FEATS))
+			le.PutUint16(b[12:], v.CountTotal)
+			le.PutUint16(b[14:], v.CountDocs)
+			b[16], b[17] = v.TextLen, v.LinkLen
+		},
+		decode: func(b []byte) Lemma {
+			return Lemma{TextStart: le.Uint32(b), LinkIdx: le.Uint32(b[4:]), FEATS: FEATS(le.Uint32(b[8:])),
+				CountTotal: le.Uint16(b[12:]), CountDocs: le.Uint16(b[14:]), TextLen: b[16], LinkLen: b[17]}
+		},
+		sample: Lemma{TextStart: 1, LinkIdx: 2, FEATS: 3, CountTotal: 4, CountDocs: 5, TextLen: 6, LinkLen: 7},
+	}
+	linkCodec = codec[Link]{
+		size: 8,
+		encode: func(b []byte, v Link) {
+			le.PutUint32(b, v.FromLemmaIdx)
+			b[4] = byte(v.Type)
+		},
+		decode: func(b []byte) Link {
+			return Link{FromLemmaIdx: le.Uint32(b), Type: LinkType(b[4])}
+		},
+		sample: Link{FromLemmaIdx: 1, Type: 2},
+	}
+	formIndexCodec = codec[formIndexEntry]{
+		size: 16,
+		encode: func(b []byte, v formIndexEntry) {
+			le.PutUint64(b, v.Hash)
+			le.PutUint32(b[8:], v.Idx)
+		},
+		decode: func(b []byte) formIndexEntry {
+			return formIndexEntry{Hash: le.Uint64(b), Idx: le.Uint32(b[8:])}
+		},
+		sample: formIndexEntry{Hash: 1, Idx: 2},
+	}
+	lemmaFormCodec = codec[lemmaForm]{
+		size: 8,
+		encode: func(b []byte, v lemmaForm) {
+			le.PutUint32(b, v.FormIdx)
+			le.PutUint32(b[4:], v.FormTextIdx)
+		},
+		decode: func(b []byte) lemmaForm {
+			return lemmaForm{FormIdx: le.Uint32(b), FormTextIdx: le.Uint32(b[4:])}
+		},
+		sample: lemmaForm{FormIdx: 1, FormTextIdx: 2},
+	}
+	ruleCodec = codec[PredictionRule]{
+		size: 20,
+		encode: func(b []byte, v PredictionRule) {
+			le.PutUint32(b, uint32(v.Tag))
+			le.PutUint32(b[4:], v.Counter)
+			le.PutUint32(b[8:], v.AppendStart)
+			le.PutUint32(b[12:], math.Float32bits(v.Score))
+			b[16], b[17] = v.AppendLen, v.Cut
+		},
+		decode: func(b []byte) PredictionRule {
+			return PredictionRule{Tag: FEATS(le.Uint32(b)), Counter: le.Uint32(b[4:]), AppendStart: le.Uint32(b[8:]),
+				Score: math.Float32frombits(le.Uint32(b[12:])), AppendLen: b[16], Cut: b[17]}
+		},
+		sample: PredictionRule{Tag: 1, Counter: 2, AppendStart: 3, Score: 4, AppendLen: 5, Cut: 6},
+	}
+	nodeCodec = codec[SuffixNode]{
+		size: 16,
+		encode: func(b []byte, v SuffixNode) {
+			le.PutUint32(b, v.ChildrenIdx)
+			le.PutUint32(b[4:], v.RulesIdx)
+			le.PutUint32(b[8:], v.Counter)
+			b[12], b[13] = v.ChildrenLen, v.RulesLen
+		},
+		decode: func(b []byte) SuffixNode {
+			return SuffixNode{ChildrenIdx: le.Uint32(b), RulesIdx: le.Uint32(b[4:]), Counter: le.Uint32(b[8:]),
+				ChildrenLen: b[12], RulesLen: b[13]}
+		},
+		sample: SuffixNode{ChildrenIdx: 1, RulesIdx: 2, Counter: 3, ChildrenLen: 4, RulesLen: 5},
+	}
+	edgeCodec = codec[Edge]{
+		size: 16,
+		encode: func(b []byte, v Edge) {
+			le.PutUint32(b, uint32(v.Char))
+			le.PutUint64(b[8:], uint64(v.NodeIdx))
+		},
+		decode: func(b []byte) Edge {
+			return Edge{Char: rune(le.Uint32(b)), NodeIdx: int(le.Uint64(b[8:]))}
+		},
+		sample: Edge{Char: 1, NodeIdx: 2},
+	}
+)
+
+// native reports whether records can be used in place: the host is
+// little-endian and decoding an encoded sample through a pointer cast gives
+// the sample back.
+func (c codec[T]) native() bool {
+	if unsafe.Sizeof(c.sample) != uintptr(c.size) {
+		return false
+	}
+	buf := make([]uint64, (c.size+7)/8)
+	b := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), c.size)
+	c.encode(b, c.sample)
+	return *(*T)(unsafe.Pointer(&buf[0])) == c.sample
+}
+
+func (c codec[T]) append(dst []byte, items []T) []byte {
+	start := len(dst)
+	dst = append(dst, make([]byte, len(items)*c.size)...)
+	for i, item := range items {
+		c.encode(dst[start+i*c.size:], item)
+	}
+	return dst
+}
+
+func (c codec[T]) view(b []byte) ([]T, error) {
+	if len(b)%c.size != 0 {
+		return nil, fmt.Errorf("section length %d is not a multiple of %d", len(b), c.size)
+	}
+	n := len(b) / c.size
+	if n == 0 {
+		return nil, nil
+	}
+	if c.native() && uintptr(unsafe.Pointer(&b[0]))%unsafe.Alignof(c.sample) == 0 {
+		return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n), nil
+	}
+
+	items := make([]T, n)
+	for i := range items {
+		items[i] = c.decode(b[i*c.size:])
+	}
+	return items, nil
+}
+
+type binarySection struct {
+	id   uint32
+	data []byte
+}
+
+// WriteBinary writes data in the binary format read by OpenBinary.
+func WriteBinary(w io.Writer, data *LemmatizerData) error {
+	if err := data.Validate(); err != nil {
+		return err
+	}
+
+	dict := data.Dictionary
+	if dict.lemmaFormsStart == nil {
+		dict.buildLemmaForms()
+	}
+	tagger := &dict.Tagger
+	oov := &data.SuffixPredictor
+
+	meta := make([]byte, 48)
+	le.PutUint64(meta, uint64(tagger.UniqueWords))
+	le.PutUint64(meta[8:], uint64(tagger.UniqueTags))
+	le.PutUint64(meta[16:], math.Float64bits(tagger.Alpha))
+	for i, lambda := range tagger.TrigramLambdas {
+		le.PutUint64(meta[24+8*i:], math.Float64bits(lambda))
+	}
+
+	var linkTypes []byte
+	names := make([]string, 0, len(dict.LinkTypes))
+	for name := range dict.LinkTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(name) > math.MaxUint8 {
+			return fmt.Errorf("link type name %q is too long", name)
+		}
+		linkTypes = append(linkTypes, byte(dict.LinkTypes[name]), byte(len(name)))
+		linkTypes = append(linkTypes, name...)
+	}
+
+	var tagTotals []byte
+	for _, tag := range sortedKeys(tagger.TagTotalCounts) {
+		tagTotals = le.AppendUint32(tagTotals, uint32(tag))
+		tagTotals = le.AppendUint32(tagTotals, 0)
+		tagTotals = le.AppendUint64(tagTotals, uint64(tagger.TagTotalCounts[tag]))
+	}
+
+	var transitions []byte
+	for _, prev := range sortedKeys(tagger.TransitionCounts) {
+		row := tagger.TransitionCounts[prev]
+		for _, curr := range sortedKeys(row) {
+			transitions = le.AppendUint32(transitions, uint32(prev))
+			transitions = le.AppendUint32(transitions, uint32(curr))
+			transitions = le.AppendUint64(transitions, uint64(row[curr]))
+		}
+	}
+
+	var tagPairs, trigrams []byte
+	for _, pair := range sortedPairs(tagger.TagPairCounts) {
+		tagPairs = le.AppendUint32(tagPairs, uint32(pair.Prev2))
+		tagPairs = le.AppendUint32(tagPairs, uint32(pair.Prev))
+		tagPairs = le.AppendUint64(tagPairs, uint64(tagger.TagPairCounts[pair]))
+	}
+	for _, pair := range sortedPairs(tagger.TrigramCounts) {
+		row := tagger.TrigramCounts[pair]
+		for _, curr := range sortedKeys(row) {
+			trigrams = le.AppendUint32(trigrams, uint32(pair.Prev2))
+			trigrams = le.AppendUint32(trigrams, uint32(pair.Prev))
+			trigrams = le.AppendUint32(trigrams, uint32(curr))
+			trigrams = le.AppendUint32(trigrams, 0)
+			trigrams = le.AppendUint64(trigrams, uint64(row[curr]))
+		}
+	}
+
+	sections := []binarySection{
+		{sectionMeta, meta},
+		{sectionLinkTypes, linkTypes},
+		{sectionTexts, []byte(dict.Texts)},
+		{sectionFormTexts, formTextCodec.append(nil, dict.FormTexts)},
+		{sectionForms, formCodec.append(nil, dict.Forms)},
+		{sectionLemmas, lemmaCodec.append(nil, dict.Lemmas)},
+		{sectionLinks, linkCodec.append(nil, dict.Links)},
+		{sectionFormIndex, formIndexCodec.append(nil, dict.sortedFormIndex())},
+		{sectionLemmaFormsStart, uint32Codec.append(nil, dict.lemmaFormsStart)},
+		{sectionLemmaForms, lemmaFormCodec.append(nil, dict.lemmaForms)},
+		{sectionTagTotals, tagTotals},
+		{sectionTransitions, transitions},
+		{sectionTagPairs, tagPairs},
+		{sectionTrigrams, trigrams},
+		{sectionRules, ruleCodec.append(nil, oov.RulePool)},
+		{sectionNodes, nodeCodec.append(nil, oov.NodePool)},
+		{sectionEdges, edgeCodec.append(nil, oov.EdgesPool)},
+		{sectionAppendTexts, []byte(oov.AppendTexts)},
+	}
+
+	header := make([]byte, binaryHeaderSize+binaryEntrySize*len(sections))
+	copy(header, binaryMagic[:])
+	le.PutUint32(header[8:], BinaryVersion)
+	le.PutUint32(header[12:], uint32(len(sections)))
+
+	offset := align8(uint64(len(header) + 4))
+	for i, s := range sections {
+		entry := header[binaryHeaderSize+binaryEntrySize*i:]
+		le.PutUint32(entry, s.id)
+		le.PutUint32(entry[4:], crc32.Checksum(s.data, crcTable))
+		le.PutUint64(entry[8:], offset)
+		le.PutUint64(entry[16:], uint64(len(s.data)))
+		offset = align8(offset + uint64(len(s.data)))
+	}
+	header = le.AppendUint32(header, crc32.Checksum(header, crcTable))
+
+	written := uint64(0)
+	write := func(b []byte) error {
+		n, err := w.Write(b)
+		written += uint64(n)
+		return err
+	}
+	pad := func() error {
+		return write(make([]byte, align8(written)-written))
+	}
+
+	if err := write(header); err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if err := pad(); err != nil {
+			return err
+		}
+		if err := write(s.data); err != nil {
+			return err
+		}
+	}
+	return pad()
+}
+
+func align8(n uint64) uint64 {
+	return (n + 7) &^ 7
+}
+
+func sortedKeys[V any](m map[FEATS]V) []FEATS {
+	keys := make([]FEATS, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedPairs[V any](m map[TagPair]V) []TagPair {
+	keys := make([]TagPair, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b TagPair) int {
+		if c := cmp.Compare(a.Prev2, b.Prev2); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Prev, b.Prev)
+	})
+	return keys
+}
+
+// sortedFormIndex flattens FormTextIndex and FormTextChains into one array
+// sorted by hash, keeping the FormTextIndex entry first among equal hashes so
+// lookups resolve exactly as they do through the maps.
+func (d *DictionaryBase) sortedFormIndex() []formIndexEntry {
+	if d.formIndex != nil {
+		return d.formIndex
+	}
+
+	var entries []formIndexEntry
+	if d.FormTextIndex == nil {
+		for i, ft := range d.FormTexts {
+			entries = append(entries, formIndexEntry{Hash: formTextHash(d.formText(ft)), Idx: uint32(i)})
+		}
+	} else {
+		for hash, idx := range d.FormTextIndex {
+			entries = append(entries, formIndexEntry{Hash: hash, Idx: idx})
+			for _, idx := range d.FormTextChains[hash] {
+				entries = append(entries, formIndexEntry{Hash: hash, Idx: idx})
+			}
+		}
+	}
+
+	slices.SortStableFunc(entries, func(a, b formIndexEntry) int {
+		return cmp.Compare(a.Hash, b.Hash)
+	})
+	return entries
+}
+
+func decodeBinary(b []byte) (LemmatizerData, error) {
+	var data LemmatizerData
+
+	sections, err := readSections(b)
+	if err != nil {
+		return data, err
+	}
+
+	for _, id := range []uint32{sectionMeta, sectionLinkTypes, sectionTexts, sectionFormTexts, sectionForms, sectionLemmas,
+		sectionLinks, sectionFormIndex, sectionLemmaFormsStart, sectionLemmaForms, sectionTagTotals, sectionTransitions,
+		sectionTagPairs, sectionTrigrams, sectionRules, sectionNodes, sectionEdges, sectionAppendTexts} {
+		if _, ok := sections[id]; !ok {
+			return data, fmt.Errorf("missing section %d", id)
+		}
+	}
+
+	dict := &data.Dictionary
+	tagger := &dict.Tagger
+	oov := &data.SuffixPredictor
+
+	meta := sections[sectionMeta]
+	if len(meta) != 48 {
+		return data, fmt.Errorf("bad meta section length %d", len(meta))
+	}
+	tagger.UniqueWords = int(le.Uint64(meta))
+	tagger.UniqueTags = int(le.Uint64(meta[8:]))
+	tagger.Alpha = math.Float64frombits(le.Uint64(meta[16:]))
+	for i := range tagger.TrigramLambdas {
+		tagger.TrigramLambdas[i] = math.Float64frombits(le.Uint64(meta[24+8*i:]))
+	}
+
+	dict.LinkTypes = map[string]LinkType{}
+	for rest := sections[sectionLinkTypes]; len(rest) > 0; {
+		if len(rest) < 2 || len(rest) < 2+int(rest[1]) {
+			return data, errors.New("truncated link types section")
+		}
+		dict.LinkTypes[string(rest[2:2+int(rest[1])])] = LinkType(rest[0])
+		rest = rest[2+int(rest[1]):]
+	}
+
+	dict.Texts = bytesToString(sections[sectionTexts])
+	oov.AppendTexts = bytesToString(sections[sectionAppendTexts])
+
+	views := []struct {
+		name string
+		err  error
+	}{
+		{"form texts", view(formTextCodec, sections[sectionFormTexts], &dict.FormTexts)},
+		{"forms", view(formCodec, sections[sectionForms], &dict.Forms)},
+		{"lemmas", view(lemmaCodec, sections[sectionLemmas], &dict.Lemmas)},
+		{"links", view(linkCodec, sections[sectionLinks], &dict.Links)},
+		{"form index", view(formIndexCodec, sections[sectionFormIndex], &dict.formIndex)},
+		{"lemma forms start", view(uint32Codec, sections[sectionLemmaFormsStart], &dict.lemmaFormsStart)},
+		{"lemma forms", view(lemmaFormCodec, sections[sectionLemmaForms], &dict.lemmaForms)},
+		{"rules", view(ruleCodec, sections[sectionRules], &oov.RulePool)},
+		{"nodes", view(nodeCodec, sections[sectionNodes], &oov.NodePool)},
+		{"edges", view(edgeCodec, sections[sectionEdges], &oov.EdgesPool)},
+	}
+	for _, v := range views {
+		if v.err != nil {
+			return data, fmt.Errorf("%s: %w", v.name, v.err)
+		}
+	}
+	if dict.formIndex == nil {
+		dict.formIndex = []formIndexEntry{}
+	}
+	if dict.lemmaFormsStart == nil {
+		dict.lemmaFormsStart = []uint32{0}
+	}
+
+	tagger.TagTotalCounts = map[FEATS]int{}
+	err = readRecords(sections[sectionTagTotals], 16, func(r []byte) {
+		tagger.TagTotalCounts[FEATS(le.Uint32(r))] = int(le.Uint64(r[8:]))
+	})
+	if err != nil {
+		return data, fmt.Errorf("tag totals: %w", err)
+	}
+
+	tagger.TransitionCounts = map[FEATS]map[FEATS]int{}
+	err = readRecords(sections[sectionTransitions], 16, func(r []byte) {
+		prev := FEATS(le.Uint32(r))
+		if tagger.TransitionCounts[prev] == nil {
+			tagger.TransitionCounts[prev] = map[FEATS]int{}
+		}
+		tagger.TransitionCounts[prev][FEATS(le.Uint32(r[4:]))] = int(le.Uint64(r[8:]))
+	})
+	if err != nil {
+		return data, fmt.Errorf("transitions: %w", err)
+	}
+
+	if len(sections[sectionTagPairs]) > 0 || len(sections[sectionTrigrams]) > 0 {
+		tagger.TagPairCounts = map[TagPair]int{}
+		tagger.TrigramCounts = map[TagPair]map[FEATS]int{}
+	}
+	err = readRecords(sections[sectionTagPairs], 16, func(r []byte) {
+		tagger.TagPairCounts[TagPair{Prev2: FEATS(le.Uint32(r)), Prev: FEATS(le.Uint32(r[4:]))}] = int(le.Uint64(r[8:]))
+	})
+	if err != nil {
+		return data, fmt.Errorf("tag pairs: %w", err)
+	}
+	err = readRecords(sections[sectionTrigrams], 24, func(r []byte) {
+		pair := TagPair{Prev2: FEATS(le.Uint32(r)), Prev: FEATS(le.Uint32(r[4:]))}
+		if tagger.TrigramCounts[pair] == nil {
+			tagger.TrigramCounts[pair] = map[FEATS]int{}
+		}
+		tagger.TrigramCounts[pair][FEATS(le.Uint32(r[8:]))] = int(le.Uint64(r[16:]))
+	})
+	if err != nil {
+		return data, fmt.Errorf("trigrams: %w", err)
+	}
+
+	if err := data.Validate(); err != nil {
+		return LemmatizerData{}, err
+	}
+	return data, nil
+}
+
+func view[T comparable](c codec[T], b []byte, dst *[]T) error {
+	items, err := c.view(b)
+	*dst = items
+	return err
+}
+
+func readRecords(b []byte, size int, fn func([]byte)) error {
+	if len(b)%size != 0 {
+		return fmt.Errorf("section length %d is not a multiple of %d", len(b), size)
+	}
+	for i := 0; i < len(b); i += size {
+		fn(b[i : i+size])
+	}
+	return nil
+}
+
+func bytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+func readSections(b []byte) (map[uint32][]byte, error) {
+	if len(b) < binaryHeaderSize || !bytes.Equal(b[:8], binaryMagic[:]) {
+		return nil, errors.New("not a lemmatizer data file")
+	}
+	if version := le.Uint32(b[8:]); version != BinaryVersion {
+		return nil, fmt.Errorf("unsupported data version %d, expected %d", version, BinaryVersion)
+	}
+
+	count := uint64(le.Uint32(b[12:]))
+	headerLen := binaryHeaderSize + binaryEntrySize*count
+	if uint64(len(b)) < headerLen+4 {
+		return nil, errors.New("truncated header")
+	}
+	if crc32.Checksum(b[:headerLen], crcTable) != le.Uint32(b[headerLen:]) {
+		return nil, errors.New("header checksum mismatch")
+	}
+
+	sections := make(map[uint32][]byte, count)
+	for i := range count {
+		entry := b[binaryHeaderSize+binaryEntrySize*i:]
+		id, crc := le.Uint32(entry), le.Uint32(entry[4:])
+		offset, length := le.Uint64(entry[8:]), le.Uint64(entry[16:])
+		if offset > uint64(len(b)) || length > uint64(len(b))-offset {
+			return nil, fmt.Errorf("section %d is out of bounds", id)
+		}
+
+		data := b[offset : offset+length : offset+length]
+		if crc32.Checksum(data, crcTable) != crc {
+			return nil, fmt.Errorf("section %d checksum mismatch", id)
+		}
+		sections[id] = data
+	}
+
+	return sections, nil
+}
+
+// MappedData is LemmatizerData whose pools live in a read-only memory
+// mapping of a binary data file, shared with every other process mapping it.
+// It must not be closed while a Lemmatizer built from it is in use, and its
+// pools must not be touched after Close. Strings returned by such a Lemmatizer
+// are copied out of the mapping and stay valid.
+type MappedData struct {
+	LemmatizerData
+	mapping []byte
+}
+
+// OpenBinary maps a file written by WriteBinary and verifies its checksums.
+func OpenBinary(path string) (*MappedData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < binaryHeaderSize || info.Size() > math.MaxInt {
+		return nil, fmt.Errorf("%s: not a lemmatizer data file", path)
+	}
+
+	mapping, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBinary(mapping)
+	if err != nil {
+		unmapFile(mapping)
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	data.Dictionary.mapped = true
+	data.SuffixPredictor.mapped = true
+	return &MappedData{LemmatizerData: data, mapping: mapping}, nil
+}
+
+func (m *MappedData) Close() error {
+	if m.mapping == nil {
+		return nil
+	}
+	err := unmapFile(m.mapping)
+	m.mapping = nil
+	return err
+}
@@ -0,0 +1,177 @@
+package nlp
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func binaryFixtureData() LemmatizerData {
+	data := suffixFixtureData()
+	tagger := &data.Dictionary.Tagger
+	tagger.Alpha = 0.25
+	tagger.TrigramLambdas = [3]float64{0.1, 0.3, 0.6}
+	tagger.TagPairCounts = map[TagPair]int{{Prev2: startTag & BigramMask, Prev: noun(Fem, Nom, Sing)}: 3}
+	tagger.TrigramCounts = map[TagPair]map[FEATS]int{
+		{Prev2: startTag & BigramMask, Prev: noun(Fem, Nom, Sing)}: {verb(Fin, Fem, Sing): 2, noun(Fem, Gen, Sing): 1},
+	}
+	return data
+}
+
+func writeBinaryFile(t *testing.T, data LemmatizerData) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "data.bin")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, WriteBinary(file, &data))
+	require.NoError(t, file.Close())
+	return path
+}
+
+var update = flag.Bool("update", false, "rewrite the files under testdata")
+
+// testdata/fixture.bin is the fixture data for the tests of the commands; after
+// changing the fixture, regenerate it with go test -run TestFixtureFile -update.
+func TestFixtureFile(t *testing.T) {
+	data := binaryFixtureData()
+	path := filepath.Join("testdata", "fixture.bin")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, data.SaveFile(path))
+	}
+
+	var want bytes.Buffer
+	require.NoError(t, data.Save(&want))
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(want.Bytes(), got), "%s is out of date, rerun with -update", path)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	data := binaryFixtureData()
+	mapped, err := OpenBinary(writeBinaryFile(t, data))
+	require.NoError(t, err)
+	defer mapped.Close()
+
+	got := mapped.LemmatizerData
+	assert.Equal(t, data.Dictionary.Tagger, got.Dictionary.Tagger)
+	assert.Equal(t, data.Dictionary.LinkTypes, got.Dictionary.LinkTypes)
+	assert.Equal(t, data.Dictionary.Texts, got.Dictionary.Texts)
+	assert.Equal(t, data.Dictionary.FormTexts, got.Dictionary.FormTexts)
+	assert.Equal(t, data.Dictionary.Forms, got.Dictionary.Forms)
+	assert.Equal(t, data.Dictionary.Lemmas, got.Dictionary.Lemmas)
+	assert.Equal(t, data.Dictionary.Links, got.Dictionary.Links)
+	assert.Equal(t, data.SuffixPredictor.RulePool, got.SuffixPredictor.RulePool)
+	assert.Equal(t, data.SuffixPredictor.NodePool, got.SuffixPredictor.NodePool)
+	assert.Equal(t, data.SuffixPredictor.EdgesPool, got.SuffixPredictor.EdgesPool)
+	assert.Equal(t, data.SuffixPredictor.AppendTexts, got.SuffixPredictor.AppendTexts)
+
+	if formCodec.native() {
+		start := uintptr(unsafe.Pointer(&mapped.mapping[0]))
+		forms := uintptr(unsafe.Pointer(&got.Dictionary.Forms[0]))
+		assert.True(t, forms >= start && forms < start+uintptr(len(mapped.mapping)), "forms are not used in place")
+	}
+}
+
+func TestBinaryLookupsMatch(t *testing.T) {
+	data := binaryFixtureData()
+	mapped, err := OpenBinary(writeBinaryFile(t, data))
+	require.NoError(t, err)
+	defer mapped.Close()
+
+	expected, err := NewLemmatizer(data)
+	require.NoError(t, err)
+	actual, err := NewLemmatizer(mapped.LemmatizerData)
+	require.NoError(t, err)
+
+	words := []string{"абырвалг", "сталями", "домище", ""}
+	for _, f := range fixtureForms {
+		words = append(words, f.form)
+	}
+	for _, word := range words {
+		assert.Equal(t, expected.Analyze(word), actual.Analyze(word), word)
+		assert.Equal(t, expected.LemmatizeWord(word), actual.LemmatizeWord(word), word)
+		assert.Equal(t, expected.Lexeme(word), actual.Lexeme(word), word)
+	}
+
+	for _, text := range []string{"Мама мыла раму.", "В новом доме стали читать книги о Москве. Быстрее!"} {
+		assert.Equal(t, expected.AnalyzeText(text), actual.AnalyzeText(text), text)
+	}
+}
+
+func TestBinaryResultsOutliveClose(t *testing.T) {
+	mapped, err := OpenBinary(writeBinaryFile(t, binaryFixtureData()))
+	require.NoError(t, err)
+	l, err := NewLemmatizer(mapped.LemmatizerData)
+	require.NoError(t, err)
+
+	lemmas := l.LemmatizeText("Мама мыла раму, читающий сталями")
+	lexeme := l.Lexeme("дом")
+	parses := l.Analyze("читающий")
+	predictions := l.base.SuffixPredictor.Predict("сталями")
+	require.NotEmpty(t, lexeme)
+	require.NotEmpty(t, parses[0].Chain)
+	require.NotEmpty(t, predictions)
+	require.NoError(t, mapped.Close())
+
+	assert.Equal(t, []string{"мама", "мыть", "рама", ",", "читать", predictions[0].Lemma}, lemmas)
+	assert.Equal(t, "дом", lexeme[0].Text)
+	assert.Equal(t, "читающий", parses[0].Chain[0].To)
+	assert.NotEmpty(t, strings.Clone(predictions[0].Lemma))
+}
+
+func TestBinaryCollisions(t *testing.T) {
+	data := binaryFixtureData()
+	collideFormTexts(&data.Dictionary, "мамы", "абырвалг")
+	mapped, err := OpenBinary(writeBinaryFile(t, data))
+	require.NoError(t, err)
+	defer mapped.Close()
+
+	expected, err := NewLemmatizer(data)
+	require.NoError(t, err)
+	actual, err := NewLemmatizer(mapped.LemmatizerData)
+	require.NoError(t, err)
+
+	for _, word := range []string{"мама", "мыло", "стали", "новые", "быстрее"} {
+		assert.NotEmpty(t, expected.getForms(word), word)
+		assert.Equal(t, expected.getForms(word), actual.getForms(word), word)
+	}
+	for _, word := range []string{"мамы", "абырвалг"} {
+		assert.Nil(t, actual.getForms(word), word)
+	}
+}
+
+func TestBinaryCorruption(t *testing.T) {
+	var buf bytes.Buffer
+	data := binaryFixtureData()
+	require.NoError(t, WriteBinary(&buf, &data))
+	valid := buf.Bytes()
+
+	_, err := decodeBinary(valid)
+	require.NoError(t, err)
+
+	corrupt := func(fn func(b []byte) []byte) error {
+		_, err := decodeBinary(fn(bytes.Clone(valid)))
+		return err
+	}
+
+	assert.ErrorContains(t, corrupt(func(b []byte) []byte { b[0] = 'X'; return b }), "not a lemmatizer data file")
+	assert.ErrorContains(t, corrupt(func(b []byte) []byte { b[8] = 99; return b }), "unsupported data version")
+	assert.ErrorContains(t, corrupt(func(b []byte) []byte { b[20]++; return b }), "header checksum")
+	assert.ErrorContains(t, corrupt(func(b []byte) []byte { b[len(b)-20]++; return b }), "checksum mismatch")
+	assert.Error(t, corrupt(func(b []byte) []byte { return b[:len(b)/2] }))
+	assert.Error(t, corrupt(func(b []byte) []byte { return b[:10] }))
+
+	path := filepath.Join(t.TempDir(), "empty.bin")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	_, err = OpenBinary(path)
+	assert.Error(t, err)
+}
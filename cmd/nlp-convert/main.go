@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+func main() {
+	dictPath := flag.String("dict", "", "path to the gob dictionary")
+	oovPath := flag.String("oov", "", "path to the gob OOV suffix model")
+	outPath := flag.String("out", "", "path to write the binary data file to")
+	flag.Parse()
+
+	if *dictPath == "" || *outPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: nlp-convert -dict DICT [-oov OOV] -out FILE")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	dict, err := nlp.LoadDictionary(*dictPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data := nlp.LemmatizerData{Dictionary: *dict}
+	if *oovPath != "" {
+		oov, err := nlp.LoadOOV(*oovPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		data.SuffixPredictor = *oov
+	}
+
+	if err := data.SaveFile(*outPath); err != nil {
+		log.Fatal(err)
+	}
+
+	mapped, err := nlp.OpenBinary(*outPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	mapped.Close()
+}
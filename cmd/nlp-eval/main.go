@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+func main() {
+	dictPath := flag.String("dict", "", "path to the dictionary")
+	oovPath := flag.String("oov", "", "path to the OOV suffix model")
+	goldPath := flag.String("gold", "", "path to the gold CoNLL-U file")
+	goldTokens := flag.Bool("gold-tokens", false, "use gold tokenization instead of Tokenize")
+	profileName := flag.String("profile", "ud", "lemmatization profile: search, ud or dictionary")
+	flag.Parse()
+
+	if *dictPath == "" || *goldPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: nlp-eval -dict DICT [-oov OOV] -gold FILE.conllu [-gold-tokens] [-profile NAME]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	dict, err := nlp.LoadDictionary(*dictPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data := nlp.LemmatizerData{Dictionary: *dict}
+	if *oovPath != "" {
+		oov, err := nlp.LoadOOV(*oovPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		data.SuffixPredictor = *oov
+	}
+
+	profile, err := nlp.ProfileByName(*profileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lem, err := nlp.NewLemmatizer(data, nlp.WithProfile(profile))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Open(*goldPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	gold, err := nlp.ReadCoNLLU(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := lem.Evaluate(gold, nlp.EvalOptions{GoldTokenization: *goldTokens})
+	if err := report.Print(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+func main() {
+	dictPath := flag.String("dict", "", "path to the dictionary")
+	oovPath := flag.String("oov", "", "path to the OOV suffix model")
+	dataPath := flag.String("data", "", "path to a binary data file written by nlp-convert, used instead of -dict and -oov")
+	userPath := flag.String("user", "", "path to a user dictionary TSV layered over the dictionary")
+	profileName := flag.String("profile", "search", "lemmatization profile: search, ud or dictionary")
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	maxBody := flag.Int64("max-body", 1<<20, "maximum request body size in bytes")
+	maxBatch := flag.Int("max-batch", 1000, "maximum number of items in a batch request")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "time to wait for requests in flight on shutdown")
+	flag.Parse()
+
+	if *dictPath == "" && *dataPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: nlp-server {-dict DICT [-oov OOV] | -data FILE} [-addr ADDR]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	var data nlp.LemmatizerData
+	if *dataPath != "" {
+		mapped, err := nlp.OpenBinary(*dataPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mapped.Close()
+		data = mapped.LemmatizerData
+	} else {
+		dict, err := nlp.LoadDictionary(*dictPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		data.Dictionary = *dict
+		if *oovPath != "" {
+			oov, err := nlp.LoadOOV(*oovPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			data.SuffixPredictor = *oov
+		}
+	}
+
+	profile, err := nlp.ProfileByName(*profileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []nlp.Option{nlp.WithProfile(profile)}
+	if *userPath != "" {
+		user, err := nlp.LoadUserDictionary(*userPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, nlp.WithUserDictionary(user))
+	}
+
+	lem, err := nlp.NewLemmatizer(data, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	srv := &http.Server{
+		Handler:           newServer(lem, limits{maxBody: *maxBody, maxBatch: *maxBatch}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("listening on %s", ln.Addr())
+	if err := serve(ctx, srv, ln, *shutdownTimeout); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// serve runs srv on ln until ctx is done, then stops accepting connections and
+// waits up to timeout for the requests in flight to finish.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		log.Print("shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
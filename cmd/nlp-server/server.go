@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+type limits struct {
+	maxBody  int64
+	maxBatch int
+}
+
+type server struct {
+	lem      *nlp.Lemmatizer
+	keywords *nlp.Keywords
+	limits   limits
+}
+
+func newServer(lem *nlp.Lemmatizer, lim limits) http.Handler {
+	s := &server{lem: lem, keywords: lem.Keywords(), limits: lim}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.Handle("POST /tokenize", endpoint(s, s.tokenize))
+	mux.Handle("POST /lemmatize", endpoint(s, s.lemmatize))
+	mux.Handle("POST /analyze", endpoint(s, s.analyze))
+	mux.Handle("POST /inflect", endpoint(s, s.inflect))
+	return mux
+}
+
+type textRequest struct {
+	Text string `json:"text"`
+}
+
+type tokenJSON struct {
+	Text    string `json:"text"`
+	Surface string `json:"surface"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	Type    string `json:"type"`
+}
+
+type tokenizeResponse struct {
+	Tokens []tokenJSON `json:"tokens"`
+}
+
+type lemmatizeResponse struct {
+	Lemmas []string `json:"lemmas"`
+}
+
+type analyzedTokenJSON struct {
+	tokenJSON
+	Lemma      string  `json:"lemma"`
+	UPOS       string  `json:"upos"`
+	Feats      string  `json:"feats"`
+	Confidence float64 `json:"confidence"`
+}
+
+type analyzeResponse struct {
+	Sentences [][]analyzedTokenJSON `json:"sentences"`
+}
+
+type inflectRequest struct {
+	Lemma string `json:"lemma"`
+	Feats string `json:"feats"`
+}
+
+type inflectResponse struct {
+	Forms []string `json:"forms"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// badRequest marks errors caused by the request rather than the server.
+type badRequest struct{ error }
+
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) tokenize(ctx context.Context, req textRequest) (tokenizeResponse, error) {
+	tokens := nlp.Tokenize(req.Text, s.keywords)
+	resp := tokenizeResponse{Tokens: make([]tokenJSON, len(tokens))}
+	for i := range tokens {
+		t := &tokens[i]
+		resp.Tokens[i] = tokenJSON{Text: t.Text(), Surface: t.Surface(), Start: t.Start(), End: t.End(), Type: t.Type().String()}
+	}
+	return resp, nil
+}
+
+func (s *server) lemmatize(ctx context.Context, req textRequest) (lemmatizeResponse, error) {
+	return lemmatizeResponse{Lemmas: s.lem.LemmatizeTokens(nlp.Tokenize(req.Text, s.keywords))}, nil
+}
+
+func (s *server) analyze(ctx context.Context, req textRequest) (analyzeResponse, error) {
+	sentences := s.lem.AnalyzeSentences(nlp.Tokenize(req.Text, s.keywords))
+	resp := analyzeResponse{Sentences: make([][]analyzedTokenJSON, len(sentences))}
+	for i, sentence := range sentences {
+		resp.Sentences[i] = make([]analyzedTokenJSON, len(sentence))
+		for j, t := range sentence {
+			resp.Sentences[i][j] = analyzedTokenJSON{
+				tokenJSON:  tokenJSON{Text: t.Text, Surface: t.Surface, Start: t.Start, End: t.End, Type: t.Type.String()},
+				Lemma:      t.Lemma,
+				UPOS:       t.POS.UPOS(),
+				Feats:      t.FEATS.UDFeatures(),
+				Confidence: t.Confidence,
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (s *server) inflect(ctx context.Context, req inflectRequest) (inflectResponse, error) {
+	if req.Lemma == "" {
+		return inflectResponse{}, badRequest{errors.New("lemma is required")}
+	}
+	target, err := nlp.ParseFEATS(req.Feats)
+	if err != nil {
+		return inflectResponse{}, badRequest{err}
+	}
+
+	forms := s.lem.Inflect(nlp.Normalize(req.Lemma), target)
+	if forms == nil {
+		forms = []string{}
+	}
+	return inflectResponse{Forms: forms}, nil
+}
+
+// endpoint decodes a single request object or a JSON array of them and
+// answers with a single response or an array in the same order.
+func endpoint[Req, Resp any](s *server, fn func(context.Context, Req) (Resp, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.limits.maxBody)).Decode(&body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+				return
+			}
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+			var reqs []Req
+			if err := json.Unmarshal(trimmed, &reqs); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if len(reqs) > s.limits.maxBatch {
+				writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch has %d items, the limit is %d", len(reqs), s.limits.maxBatch))
+				return
+			}
+
+			resps := make([]Resp, len(reqs))
+			for i, req := range reqs {
+				if err := r.Context().Err(); err != nil {
+					return
+				}
+				resp, err := fn(r.Context(), req)
+				if err != nil {
+					writeFnError(w, fmt.Errorf("item %d: %w", i, err))
+					return
+				}
+				resps[i] = resp
+			}
+			writeJSON(w, http.StatusOK, resps)
+			return
+		}
+
+		var req Req
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			writeFnError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+func writeFnError(w http.ResponseWriter, err error) {
+	var bad badRequest
+	if errors.As(err, &bad) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
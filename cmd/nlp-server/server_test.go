@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oleg-safonov/nlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureLemmatizer(t *testing.T) *nlp.Lemmatizer {
+	t.Helper()
+
+	data, err := nlp.LoadFile(filepath.Join("..", "..", "testdata", "fixture.bin"))
+	require.NoError(t, err)
+	lem, err := nlp.NewLemmatizer(*data)
+	require.NoError(t, err)
+	return lem
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(newServer(fixtureLemmatizer(t), limits{maxBody: 1024, maxBatch: 3}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, path, body string, resp any) int {
+	t.Helper()
+
+	r, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer r.Body.Close()
+
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(r.Body).Decode(resp))
+	return r.StatusCode
+}
+
+func TestHealth(t *testing.T) {
+	srv := newTestServer(t)
+
+	r, err := http.Get(srv.URL + "/health")
+	require.NoError(t, err)
+	defer r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+}
+
+func TestTokenize(t *testing.T) {
+	srv := newTestServer(t)
+
+	var resp tokenizeResponse
+	require.Equal(t, http.StatusOK, post(t, srv, "/tokenize", `{"text": "В доме."}`, &resp))
+	assert.Equal(t, []tokenJSON{
+		{Text: "в", Surface: "В", Start: 0, End: 2, Type: "WORD"},
+		{Text: "доме", Surface: "доме", Start: 3, End: 11, Type: "WORD"},
+		{Text: ".", Surface: ".", Start: 11, End: 12, Type: "PUNCT"},
+	}, resp.Tokens)
+}
+
+func TestLemmatize(t *testing.T) {
+	srv := newTestServer(t)
+
+	var resp lemmatizeResponse
+	require.Equal(t, http.StatusOK, post(t, srv, "/lemmatize", `{"text": "В доме домов"}`, &resp))
+	assert.Equal(t, []string{"в", "дом", "дом"}, resp.Lemmas)
+
+	var batch []lemmatizeResponse
+	require.Equal(t, http.StatusOK, post(t, srv, "/lemmatize", `[{"text": "дома"}, {"text": ""}, {"text": "в дом"}]`, &batch))
+	assert.Equal(t, []lemmatizeResponse{
+		{Lemmas: []string{"дом"}},
+		{Lemmas: []string{}},
+		{Lemmas: []string{"в", "дом"}},
+	}, batch)
+}
+
+func TestAnalyze(t *testing.T) {
+	srv := newTestServer(t)
+
+	var resp analyzeResponse
+	require.Equal(t, http.StatusOK, post(t, srv, "/analyze", `{"text": "В доме. Дом"}`, &resp))
+	require.Len(t, resp.Sentences, 2)
+	require.Len(t, resp.Sentences[0], 3)
+
+	token := resp.Sentences[0][1]
+	assert.Equal(t, "дом", token.Lemma)
+	assert.Equal(t, "NOUN", token.UPOS)
+	assert.Equal(t, "Case=Loc|Gender=Masc|Number=Sing", token.Feats)
+	assert.Equal(t, "PUNCT", resp.Sentences[0][2].UPOS)
+}
+
+func TestInflect(t *testing.T) {
+	srv := newTestServer(t)
+
+	var resp inflectResponse
+	require.Equal(t, http.StatusOK, post(t, srv, "/inflect", `{"lemma": "Дом", "feats": "Case=Gen|Number=Plur"}`, &resp))
+	assert.Equal(t, []string{"домов"}, resp.Forms)
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusBadRequest, post(t, srv, "/inflect", `{"lemma": "дом", "feats": "Case=Foo"}`, &errResp))
+	assert.NotEmpty(t, errResp.Error)
+	assert.Equal(t, http.StatusBadRequest, post(t, srv, "/inflect", `[{"lemma": "дом"}, {"lemma": ""}]`, &errResp))
+	assert.Contains(t, errResp.Error, "item 1")
+}
+
+func TestLimits(t *testing.T) {
+	srv := newTestServer(t)
+
+	var errResp errorResponse
+	body := `{"text": "` + strings.Repeat("дом ", 500) + `"}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(t, srv, "/lemmatize", body, &errResp))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(t, srv, "/lemmatize", `[{}, {}, {}, {}]`, &errResp))
+	assert.Contains(t, errResp.Error, "limit is 3")
+
+	assert.Equal(t, http.StatusBadRequest, post(t, srv, "/lemmatize", `{"text": `, &errResp))
+
+	r, err := http.Get(srv.URL + "/lemmatize")
+	require.NoError(t, err)
+	r.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, r.StatusCode)
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	api := newServer(fixtureLemmatizer(t), limits{maxBody: 1024, maxBatch: 3})
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	defer once.Do(func() { close(release) })
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lemmatize" {
+			close(entered)
+			<-release
+		}
+		api.ServeHTTP(w, r)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, &http.Server{Handler: handler}, ln, 10*time.Second)
+	}()
+
+	type result struct {
+		status int
+		resp   lemmatizeResponse
+		err    error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		var res result
+		r, err := http.Post(url+"/lemmatize", "application/json", strings.NewReader(`{"text": "в доме"}`))
+		if err == nil {
+			defer r.Body.Close()
+			res.status = r.StatusCode
+			err = json.NewDecoder(r.Body).Decode(&res.resp)
+		}
+		res.err = err
+		inFlight <- res
+	}()
+	<-entered
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send interrupt: %v", err)
+	}
+	<-ctx.Done()
+
+	// The listener closes at once, while the request in flight holds serve.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	require.Eventually(t, func() bool {
+		r, err := client.Get(url + "/health")
+		if err == nil {
+			r.Body.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("serve returned with a request in flight: %v", err)
+	default:
+	}
+
+	once.Do(func() { close(release) })
+	res := <-inFlight
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, []string{"в", "дом"}, res.resp.Lemmas)
+	assert.NoError(t, <-done)
+}
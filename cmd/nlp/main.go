@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+type config struct {
+	mode      string
+	format    string
+	stopwords *nlp.Stopwords
+	keywords  *nlp.Keywords
+	noPunct   bool
+}
+
+func main() {
+	dictPath := flag.String("dict", "", "path to the dictionary")
+	oovPath := flag.String("oov", "", "path to the OOV suffix model")
+	userPath := flag.String("user", "", "path to a user dictionary TSV layered over the dictionary")
+	profileName := flag.String("profile", "search", "lemmatization profile: search, ud or dictionary")
+	mode := flag.String("mode", "lemmas", "what to output: lemmas, tokens, analyze or words (one word per input line)")
+	format := flag.String("format", "text", "output format: text, tsv, jsonl or conllu")
+	stopwordsPath := flag.String("stopwords", "", "file with stopwords to remove, one per line")
+	stoplist := flag.String("stoplist", "", "built-in stopword list to remove: search, general or aggressive")
+	dropFunctionWords := flag.Bool("drop-function-words", false, "remove prepositions, conjunctions, particles and pronouns")
+	keywordsPath := flag.String("keywords", "", "file with extra keywords kept as single tokens, one per line")
+	noPunct := flag.Bool("no-punct", false, "drop punctuation and symbols")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: nlp [flags] [FILE...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	switch *mode {
+	case "lemmas", "tokens", "analyze", "words":
+	default:
+		log.Fatalf("unknown mode %q", *mode)
+	}
+	switch *format {
+	case "text", "tsv", "jsonl":
+	case "conllu":
+		if *mode == "words" {
+			log.Fatal("conllu format is not supported in words mode")
+		}
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	cfg := config{mode: *mode, format: *format, noPunct: *noPunct}
+
+	keywords := []nlp.KeywordSet{nlp.DefaultKeywords}
+	if *keywordsPath != "" {
+		extra, err := readList(*keywordsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keywords = append(keywords, extra)
+	}
+	cfg.keywords = nlp.NewKeywords(keywords...)
+
+	var stopwords []nlp.StopwordSet
+	switch *stoplist {
+	case "":
+	case "search":
+		stopwords = append(stopwords, nlp.SearchStopwords)
+	case "general":
+		stopwords = append(stopwords, nlp.GeneralStopwords)
+	case "aggressive":
+		stopwords = append(stopwords, nlp.AggressiveStopwords)
+	default:
+		log.Fatalf("unknown stoplist %q", *stoplist)
+	}
+	if *stopwordsPath != "" {
+		list, err := readList(*stopwordsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		stopwords = append(stopwords, list)
+	}
+	if len(stopwords) > 0 || *dropFunctionWords {
+		cfg.stopwords = nlp.NewStopwords(stopwords...)
+		if *dropFunctionWords {
+			cfg.stopwords.AddPOS(nlp.FunctionWordPOS...)
+		}
+	}
+
+	var lem *nlp.Lemmatizer
+	if cfg.mode != "tokens" {
+		if *dictPath == "" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		profile, err := nlp.ProfileByName(*profileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lem, err = loadLemmatizer(*dictPath, *oovPath, *userPath, profile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	out := newWriter(os.Stdout, cfg)
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, path := range inputs {
+		if err := processFile(path, lem, cfg, out); err != nil {
+			out.Flush()
+			log.Fatal(err)
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func loadLemmatizer(dictPath, oovPath, userPath string, profile nlp.Profile) (*nlp.Lemmatizer, error) {
+	dict, err := nlp.LoadDictionary(dictPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data := nlp.LemmatizerData{Dictionary: *dict}
+	if oovPath != "" {
+		oov, err := nlp.LoadOOV(oovPath)
+		if err != nil {
+			return nil, err
+		}
+		data.SuffixPredictor = *oov
+	}
+
+	opts := []nlp.Option{nlp.WithProfile(profile)}
+	if userPath != "" {
+		user, err := nlp.LoadUserDictionary(userPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nlp.WithUserDictionary(user))
+	}
+
+	return nlp.NewLemmatizer(data, opts...)
+}
+
+func readList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if item := nlp.Normalize(line); item != "" && !strings.HasPrefix(item, "#") {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+func processFile(path string, lem *nlp.Lemmatizer, cfg config, out *writer) error {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	if cfg.mode == "words" {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			word := strings.TrimSpace(scanner.Text())
+			if word == "" {
+				continue
+			}
+			lemma := lem.LemmatizeWord(word)
+			if cfg.stopwords != nil && cfg.stopwords.IsStopword(lemma) {
+				continue
+			}
+			if err := out.WriteWord(word, lemma); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	tokenizer := nlp.NewTokenizer(r, cfg.keywords)
+	for sentence := range tokenizer.Sentences() {
+		var tokens []nlp.AnalyzedToken
+		if lem != nil {
+			tokens = lem.AnalyzeTokens(sentence)
+		} else {
+			tokens = plainTokens(sentence)
+		}
+
+		if err := out.WriteSentence(filterTokens(tokens, cfg)); err != nil {
+			return err
+		}
+	}
+	if err := tokenizer.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+func plainTokens(sentence []nlp.Token) []nlp.AnalyzedToken {
+	tokens := make([]nlp.AnalyzedToken, len(sentence))
+	for i := range sentence {
+		t := &sentence[i]
+		tokens[i] = nlp.AnalyzedToken{
+			Text:    t.Text(),
+			Surface: t.Surface(),
+			Start:   t.Start(),
+			End:     t.End(),
+			Type:    t.Type(),
+		}
+	}
+	return tokens
+}
+
+func filterTokens(tokens []nlp.AnalyzedToken, cfg config) []nlp.AnalyzedToken {
+	result := tokens[:0]
+	for _, t := range tokens {
+		if cfg.noPunct && (t.Type == nlp.TokenPunct || t.Type == nlp.TokenSym) {
+			continue
+		}
+		if cfg.stopwords != nil && (cfg.stopwords.IsStopword(t.Text) || cfg.mode != "tokens" && cfg.stopwords.Match(t.Lemma, t.POS)) {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oleg-safonov/nlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func surfaces(tokens []nlp.AnalyzedToken) []string {
+	result := make([]string, len(tokens))
+	for i, t := range tokens {
+		result[i] = t.Surface
+	}
+	return result
+}
+
+func TestFilterTokens(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+	const text = "Мама мыла раму."
+
+	lemmaStop := nlp.NewStopwords(nlp.StopwordSet{"мыть"})
+	surfaceStop := nlp.NewStopwords(nlp.StopwordSet{"мыла"})
+
+	assert.Equal(t, []string{"Мама", "раму", "."},
+		surfaces(filterTokens(lem.AnalyzeText(text), config{mode: "lemmas", stopwords: lemmaStop})))
+	assert.Equal(t, []string{"Мама", "раму", "."},
+		surfaces(filterTokens(lem.AnalyzeText(text), config{mode: "analyze", stopwords: surfaceStop})))
+	assert.Equal(t, []string{"Мама", "раму"},
+		surfaces(filterTokens(lem.AnalyzeText(text), config{mode: "lemmas", stopwords: surfaceStop, noPunct: true})))
+
+	// Without analysis only the surface text can match.
+	plain := func() []nlp.AnalyzedToken { return plainTokens(nlp.Tokenize(text, lem.Keywords())) }
+	assert.Equal(t, []string{"Мама", "мыла", "раму", "."},
+		surfaces(filterTokens(plain(), config{mode: "tokens", stopwords: lemmaStop})))
+	assert.Equal(t, []string{"Мама", "раму", "."},
+		surfaces(filterTokens(plain(), config{mode: "tokens", stopwords: surfaceStop})))
+	assert.Equal(t, []string{"Мама", "мыла", "раму"},
+		surfaces(filterTokens(plain(), config{mode: "tokens", noPunct: true})))
+}
+
+func TestProcessFile(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+	dir := t.TempDir()
+
+	textPath := filepath.Join(dir, "text.txt")
+	require.NoError(t, os.WriteFile(textPath, []byte("Мама мыла раму. В доме\n"), 0o644))
+	cfg := config{mode: "lemmas", format: "text", keywords: lem.Keywords()}
+	var buf bytes.Buffer
+	out := newWriter(&buf, cfg)
+	require.NoError(t, processFile(textPath, lem, cfg, out))
+	require.NoError(t, out.Flush())
+	assert.Equal(t, "мама мыть рама .\nв дом\n", buf.String())
+
+	wordsPath := filepath.Join(dir, "words.txt")
+	require.NoError(t, os.WriteFile(wordsPath, []byte("Мыла\n\nдомов\n"), 0o644))
+	cfg = config{mode: "words", format: "tsv", stopwords: nlp.NewStopwords(nlp.StopwordSet{"дом"})}
+	buf.Reset()
+	out = newWriter(&buf, cfg)
+	require.NoError(t, processFile(wordsPath, lem, cfg, out))
+	require.NoError(t, out.Flush())
+	assert.Equal(t, "Мыла\tмыть\n", buf.String())
+
+	assert.Error(t, processFile(filepath.Join(dir, "missing.txt"), lem, cfg, out))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/oleg-safonov/nlp"
+)
+
+type writer struct {
+	*bufio.Writer
+	cfg       config
+	sentences int
+}
+
+func newWriter(w io.Writer, cfg config) *writer {
+	return &writer{Writer: bufio.NewWriter(w), cfg: cfg}
+}
+
+type jsonToken struct {
+	Text       string  `json:"text,omitempty"`
+	Surface    string  `json:"surface"`
+	Start      int     `json:"start"`
+	End        int     `json:"end"`
+	Type       string  `json:"type,omitempty"`
+	Lemma      string  `json:"lemma,omitempty"`
+	UPOS       string  `json:"upos,omitempty"`
+	Feats      string  `json:"feats,omitempty"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type jsonSentence struct {
+	ID     int         `json:"id"`
+	Text   string      `json:"text"`
+	Tokens []jsonToken `json:"tokens"`
+}
+
+func (w *writer) WriteWord(word, lemma string) error {
+	var err error
+	switch w.cfg.format {
+	case "text":
+		_, err = fmt.Fprintln(w, lemma)
+	case "tsv":
+		_, err = fmt.Fprintf(w, "%s\t%s\n", word, lemma)
+	case "jsonl":
+		err = w.writeJSON(map[string]string{"word": word, "lemma": lemma})
+	}
+	return err
+}
+
+func (w *writer) WriteSentence(tokens []nlp.AnalyzedToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	w.sentences++
+
+	switch w.cfg.format {
+	case "text":
+		items := make([]string, len(tokens))
+		for i, t := range tokens {
+			switch w.cfg.mode {
+			case "tokens":
+				items[i] = t.Surface
+			case "lemmas":
+				items[i] = t.Lemma
+			case "analyze":
+				items[i] = t.Surface + "/" + t.Lemma + "/" + t.FEATS.POS().UPOS()
+			}
+		}
+		_, err := fmt.Fprintln(w, strings.Join(items, " "))
+		return err
+
+	case "tsv":
+		for _, t := range tokens {
+			switch w.cfg.mode {
+			case "tokens":
+				fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", t.Start, t.End, t.Type, t.Text, t.Surface)
+			case "lemmas":
+				fmt.Fprintf(w, "%s\t%s\n", t.Surface, t.Lemma)
+			case "analyze":
+				fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%.4f\n",
+					t.Start, t.End, t.Surface, t.Lemma, t.FEATS.POS().UPOS(), t.FEATS.UDFeatures(), t.Confidence)
+			}
+		}
+		_, err := w.WriteString("\n")
+		return err
+
+	case "jsonl":
+		sentence := jsonSentence{ID: w.sentences, Text: sentenceText(tokens), Tokens: make([]jsonToken, len(tokens))}
+		for i, t := range tokens {
+			token := jsonToken{Surface: t.Surface, Start: t.Start, End: t.End}
+			switch w.cfg.mode {
+			case "tokens":
+				token.Text = t.Text
+				token.Type = t.Type.String()
+			case "lemmas":
+				token.Lemma = t.Lemma
+			case "analyze":
+				token.Type = t.Type.String()
+				token.Lemma = t.Lemma
+				token.UPOS = t.FEATS.POS().UPOS()
+				token.Feats = t.FEATS.UDFeatures()
+				token.Confidence = t.Confidence
+			}
+			sentence.Tokens[i] = token
+		}
+		return w.writeJSON(sentence)
+
+	case "conllu":
+		sentence := nlp.CoNLLUSentence{
+			ID:     strconv.Itoa(w.sentences),
+			Text:   sentenceText(tokens),
+			Tokens: make([]nlp.CoNLLUToken, len(tokens)),
+		}
+		for i, t := range tokens {
+			sentence.Tokens[i] = nlp.CoNLLUToken{ID: i + 1, Form: t.Surface, Lemma: t.Lemma, FEATS: t.FEATS}
+			if i+1 < len(tokens) && tokens[i+1].Start == t.End {
+				sentence.Tokens[i].Misc = "SpaceAfter=No"
+			}
+		}
+		return nlp.WriteCoNLLU(w, []nlp.CoNLLUSentence{sentence})
+	}
+
+	return nil
+}
+
+func (w *writer) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Write(data)
+	_, err = w.WriteString("\n")
+	return err
+}
+
+func sentenceText(tokens []nlp.AnalyzedToken) string {
+	var sb strings.Builder
+	for i, t := range tokens {
+		if i > 0 && tokens[i-1].End < t.Start {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.Surface)
+	}
+	return sb.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oleg-safonov/nlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureLemmatizer(t *testing.T) *nlp.Lemmatizer {
+	t.Helper()
+
+	data, err := nlp.LoadFile(filepath.Join("..", "..", "testdata", "fixture.bin"))
+	require.NoError(t, err)
+	lem, err := nlp.NewLemmatizer(*data)
+	require.NoError(t, err)
+	return lem
+}
+
+func writeSentences(t *testing.T, cfg config, sentences ...[]nlp.AnalyzedToken) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := newWriter(&buf, cfg)
+	for _, tokens := range sentences {
+		require.NoError(t, w.WriteSentence(tokens))
+	}
+	require.NoError(t, w.Flush())
+	return buf.String()
+}
+
+func TestWriteText(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+	tokens := lem.AnalyzeText("Мама мыла раму.")
+
+	assert.Equal(t, "Мама мыла раму .\n", writeSentences(t, config{mode: "tokens", format: "text"}, tokens))
+	assert.Equal(t, "мама мыть рама .\n", writeSentences(t, config{mode: "lemmas", format: "text"}, tokens))
+	assert.Equal(t, "Мама/мама/NOUN мыла/мыть/VERB раму/рама/NOUN ././PUNCT\n",
+		writeSentences(t, config{mode: "analyze", format: "text"}, tokens))
+	assert.Empty(t, writeSentences(t, config{mode: "lemmas", format: "text"}, nil))
+}
+
+func TestWriteTSV(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+	tokens := lem.AnalyzeText("Мама мыла раму.")
+
+	assert.Equal(t, "0\t8\tWORD\tмама\tМама\n9\t17\tWORD\tмыла\tмыла\n18\t26\tWORD\tраму\tраму\n26\t27\tPUNCT\t.\t.\n\n",
+		writeSentences(t, config{mode: "tokens", format: "tsv"}, tokens))
+	assert.Equal(t, "Мама\tмама\nмыла\tмыть\nраму\tрама\n.\t.\n\n",
+		writeSentences(t, config{mode: "lemmas", format: "tsv"}, tokens))
+
+	lines := strings.Split(writeSentences(t, config{mode: "analyze", format: "tsv"}, tokens), "\n")
+	require.Len(t, lines, 6)
+	assert.Equal(t, "0\t8\tМама\tмама\tNOUN\tCase=Nom|Gender=Fem|Number=Sing\t1.0000", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "9\t17\tмыла\tмыть\tVERB\tGender=Fem|Number=Sing|VerbForm=Fin\t0.9"), lines[1])
+	assert.Equal(t, "26\t27\t.\t.\tPUNCT\t\t1.0000", lines[3])
+}
+
+func TestWriteJSONL(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+	first, second := lem.AnalyzeText("Мама мыла раму."), lem.AnalyzeText("В доме")
+
+	out := writeSentences(t, config{mode: "analyze", format: "jsonl"}, first, second)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var sentence jsonSentence
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &sentence))
+	assert.Equal(t, 1, sentence.ID)
+	assert.Equal(t, "Мама мыла раму.", sentence.Text)
+	require.Len(t, sentence.Tokens, 4)
+	token := sentence.Tokens[1]
+	assert.Equal(t, "мыть", token.Lemma)
+	assert.Equal(t, "VERB", token.UPOS)
+	assert.Equal(t, "Gender=Fem|Number=Sing|VerbForm=Fin", token.Feats)
+	assert.Equal(t, "WORD", token.Type)
+	assert.InDelta(t, first[1].Confidence, token.Confidence, 1e-12)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &sentence))
+	assert.Equal(t, 2, sentence.ID)
+	assert.Equal(t, "дом", sentence.Tokens[1].Lemma)
+
+	assert.Equal(t, `{"id":1,"text":"В доме","tokens":[{"surface":"В","start":0,"end":2,"lemma":"в"},{"surface":"доме","start":3,"end":11,"lemma":"дом"}]}`+"\n",
+		writeSentences(t, config{mode: "lemmas", format: "jsonl"}, second))
+	assert.Equal(t, `{"id":1,"text":"В доме","tokens":[{"text":"в","surface":"В","start":0,"end":2,"type":"WORD"},{"text":"доме","surface":"доме","start":3,"end":11,"type":"WORD"}]}`+"\n",
+		writeSentences(t, config{mode: "tokens", format: "jsonl"}, second))
+}
+
+func TestWriteCoNLLU(t *testing.T) {
+	lem := fixtureLemmatizer(t)
+
+	out := writeSentences(t, config{mode: "analyze", format: "conllu"}, lem.AnalyzeText("Мама мыла раму."), lem.AnalyzeText("В доме"))
+	assert.Equal(t, "# sent_id = 1\n"+
+		"# text = Мама мыла раму.\n"+
+		"1\tМама\tмама\tNOUN\t_\tCase=Nom|Gender=Fem|Number=Sing\t_\t_\t_\t_\n"+
+		"2\tмыла\tмыть\tVERB\t_\tGender=Fem|Number=Sing|VerbForm=Fin\t_\t_\t_\t_\n"+
+		"3\tраму\tрама\tNOUN\t_\tCase=Acc|Gender=Fem|Number=Sing\t_\t_\t_\tSpaceAfter=No\n"+
+		"4\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n"+
+		"\n"+
+		"# sent_id = 2\n"+
+		"# text = В доме\n"+
+		"1\tВ\tв\tADP\t_\t_\t_\t_\t_\t_\n"+
+		"2\tдоме\tдом\tNOUN\t_\tCase=Loc|Gender=Masc|Number=Sing\t_\t_\t_\t_\n"+
+		"\n", out)
+
+	sentences, err := nlp.ReadCoNLLU(strings.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, sentences, 2)
+	assert.Equal(t, "дом", sentences[1].Tokens[1].Lemma)
+}
+
+func TestWriteWord(t *testing.T) {
+	write := func(format string) string {
+		var buf bytes.Buffer
+		w := newWriter(&buf, config{mode: "words", format: format})
+		require.NoError(t, w.WriteWord("Мыла", "мыть"))
+		require.NoError(t, w.Flush())
+		return buf.String()
+	}
+
+	assert.Equal(t, "мыть\n", write("text"))
+	assert.Equal(t, "Мыла\tмыть\n", write("tsv"))
+	assert.Equal(t, `{"lemma":"мыть","word":"Мыла"}`+"\n", write("jsonl"))
+}
@@ -0,0 +1,230 @@
+package nlp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CoNLLUToken struct {
+	ID    int
+	Form  string
+	Lemma string
+	FEATS FEATS
+	// OtherFeats keeps the features read that FEATS cannot hold, such as Mood
+	// or Tense, in the form of the FEATS column; WriteCoNLLU writes them back.
+	OtherFeats string
+	Misc       string
+}
+
+func (t CoNLLUToken) SpaceAfter() bool {
+	for _, item := range strings.Split(t.Misc, "|") {
+		if item == "SpaceAfter=No" {
+			return false
+		}
+	}
+	return true
+}
+
+type CoNLLUSentence struct {
+	ID     string
+	Text   string
+	Tokens []CoNLLUToken
+}
+
+func ToCoNLLU(text string, sentences [][]AnalyzedToken) []CoNLLUSentence {
+	result := make([]CoNLLUSentence, 0, len(sentences))
+
+	for si, tokens := range sentences {
+		if len(tokens) == 0 {
+			continue
+		}
+
+		sentence := CoNLLUSentence{
+			ID:     strconv.Itoa(si + 1),
+			Text:   strings.Join(strings.Fields(text[tokens[0].Start:tokens[len(tokens)-1].End]), " "),
+			Tokens: make([]CoNLLUToken, len(tokens)),
+		}
+		for i, t := range tokens {
+			sentence.Tokens[i] = CoNLLUToken{
+				ID:    i + 1,
+				Form:  t.Surface,
+				Lemma: t.Lemma,
+				FEATS: t.FEATS,
+			}
+			if i+1 < len(tokens) && tokens[i+1].Start == t.End {
+				sentence.Tokens[i].Misc = "SpaceAfter=No"
+			}
+		}
+		result = append(result, sentence)
+	}
+
+	return result
+}
+
+func WriteCoNLLU(w io.Writer, sentences []CoNLLUSentence) error {
+	bw := bufio.NewWriter(w)
+
+	for _, s := range sentences {
+		if s.ID != "" {
+			fmt.Fprintf(bw, "# sent_id = %s\n", s.ID)
+		}
+		if s.Text != "" {
+			fmt.Fprintf(bw, "# text = %s\n", s.Text)
+		}
+		for i, t := range s.Tokens {
+			id := t.ID
+			if id == 0 {
+				id = i + 1
+			}
+			fmt.Fprintf(bw, "%d\t%s\t%s\t%s\t_\t%s\t_\t_\t_\t%s\n",
+				id, conlluField(t.Form), conlluField(t.Lemma), t.FEATS.POS().UPOS(), conlluField(featsField(t.FEATS, t.OtherFeats)), conlluField(t.Misc))
+		}
+		bw.WriteString("\n")
+	}
+
+	return bw.Flush()
+}
+
+func featsField(feats FEATS, other string) string {
+	if other == "" {
+		return feats.UDFeatures()
+	}
+	items := strings.Split(other, "|")
+	if ud := feats.UDFeatures(); ud != "" {
+		items = append(items, strings.Split(ud, "|")...)
+	}
+	sort.Strings(items)
+	return strings.Join(items, "|")
+}
+
+func conlluField(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+func ReadCoNLLU(r io.Reader) ([]CoNLLUSentence, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var sentences []CoNLLUSentence
+	var current CoNLLUSentence
+	started := false
+
+	flush := func() {
+		if started {
+			sentences = append(sentences, current)
+		}
+		current = CoNLLUSentence{}
+		started = false
+	}
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		started = true
+
+		if strings.HasPrefix(line, "#") {
+			comment := strings.TrimSpace(line[1:])
+			if key, value, ok := strings.Cut(comment, "="); ok {
+				switch strings.TrimSpace(key) {
+				case "sent_id":
+					current.ID = strings.TrimSpace(value)
+				case "text":
+					current.Text = strings.TrimSpace(value)
+				}
+			}
+			continue
+		}
+
+		cols := strings.Split(line, "\t")
+		if len(cols) != 10 {
+			return nil, fmt.Errorf("line %d: expected 10 columns, got %d", lineNum, len(cols))
+		}
+		if strings.ContainsAny(cols[0], "-.") {
+			continue
+		}
+
+		id, err := strconv.Atoi(cols[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad token id %q", lineNum, cols[0])
+		}
+
+		feats, other, err := parseUDColumns(cols[3], cols[5], false)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		current.Tokens = append(current.Tokens, CoNLLUToken{
+			ID:         id,
+			Form:       cols[1],
+			Lemma:      unconlluField(cols[2]),
+			FEATS:      feats,
+			OtherFeats: strings.Join(other, "|"),
+			Misc:       unconlluField(cols[9]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return sentences, nil
+}
+
+func unconlluField(s string) string {
+	if s == "_" {
+		return ""
+	}
+	return s
+}
+
+// parseUDColumns parses the UPOS and FEATS columns; unless strict, it returns
+// the features FEATS cannot hold instead of failing on them.
+func parseUDColumns(upos, feats string, strict bool) (FEATS, []string, error) {
+	var result FEATS
+	var other []string
+
+	if upos != "" && upos != "_" {
+		pos, ok := parsePOS(upos)
+		if !ok && strict {
+			return 0, nil, fmt.Errorf("unknown POS %q", upos)
+		}
+		result = result.SetPOS(pos)
+	}
+
+	if feats == "" || feats == "_" {
+		return result, nil, nil
+	}
+
+	for _, feat := range strings.Split(feats, "|") {
+		value, ok := udFeatureValues[feat]
+		if !ok {
+			if strict {
+				return 0, nil, fmt.Errorf("unknown feature %q", feat)
+			}
+			other = append(other, feat)
+			continue
+		}
+		if result&value.mask() != 0 {
+			if strict {
+				return 0, nil, fmt.Errorf("duplicate feature %q", feat)
+			}
+			result &^= value.mask()
+		}
+		result |= value
+	}
+
+	return result, other, nil
+}
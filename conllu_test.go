@@ -0,0 +1,71 @@
+package nlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCoNLLU(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	text := "Мама мыла раму. Он читает книги."
+	sentences := ToCoNLLU(text, l.AnalyzeSentences(Tokenize(text, l.keywords)))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCoNLLU(&buf, sentences))
+
+	expected := "# sent_id = 1\n" +
+		"# text = Мама мыла раму.\n" +
+		"1\tМама\tмама\tNOUN\t_\tCase=Nom|Gender=Fem|Number=Sing\t_\t_\t_\t_\n" +
+		"2\tмыла\tмыть\tVERB\t_\tGender=Fem|Number=Sing|VerbForm=Fin\t_\t_\t_\t_\n" +
+		"3\tраму\tрама\tNOUN\t_\tCase=Acc|Gender=Fem|Number=Sing\t_\t_\t_\tSpaceAfter=No\n" +
+		"4\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n" +
+		"\n" +
+		"# sent_id = 2\n" +
+		"# text = Он читает книги.\n" +
+		"1\tОн\tон\tPRON\t_\tCase=Nom|Gender=Masc|Number=Sing|Person=3\t_\t_\t_\t_\n" +
+		"2\tчитает\tчитать\tVERB\t_\tNumber=Sing|Person=3|VerbForm=Fin\t_\t_\t_\t_\n" +
+		"3\tкниги\tкнига\tNOUN\t_\tCase=Gen|Gender=Fem|Number=Sing\t_\t_\t_\tSpaceAfter=No\n" +
+		"4\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n" +
+		"\n"
+	assert.Equal(t, expected, buf.String())
+
+	read, err := ReadCoNLLU(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, sentences, read)
+}
+
+func TestReadCoNLLU(t *testing.T) {
+	input := "# newdoc id = x\n" +
+		"# sent_id = s1\n" +
+		"# text = Я пошёл.\n" +
+		"1\tЯ\tя\tPRON\t_\tCase=Nom|Number=Sing|Person=1\t2\tnsubj\t_\t_\n" +
+		"2-3\tпошёл.\t_\t_\t_\t_\t_\t_\t_\t_\n" +
+		"2\tпошёл\tпойти\tVERB\t_\tAspect=Perf|Gender=Masc|Mood=Ind|Number=Sing|Tense=Past|VerbForm=Fin|Voice=Act\t0\troot\t_\tSpaceAfter=No\n" +
+		"3\t.\t.\tPUNCT\t_\t_\t2\tpunct\t_\t_\n"
+
+	sentences, err := ReadCoNLLU(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, sentences, 1)
+	assert.Equal(t, "s1", sentences[0].ID)
+	assert.Equal(t, "Я пошёл.", sentences[0].Text)
+	require.Len(t, sentences[0].Tokens, 3)
+
+	tok := sentences[0].Tokens[1]
+	assert.Equal(t, "пойти", tok.Lemma)
+	assert.Equal(t, "VERB|VerbForm=Fin|Gender=Masc|Number=Sing|Aspect=Perf|Voice=Act", tok.FEATS.String())
+	assert.Equal(t, "Mood=Ind|Tense=Past", tok.OtherFeats)
+	assert.False(t, tok.SpaceAfter())
+	assert.Equal(t, Person1, sentences[0].Tokens[0].FEATS.Person())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCoNLLU(&buf, sentences))
+	assert.Contains(t, buf.String(), "\tAspect=Perf|Gender=Masc|Mood=Ind|Number=Sing|Tense=Past|VerbForm=Fin|Voice=Act\t")
+
+	_, err = ReadCoNLLU(strings.NewReader("1\tбитая\tстрока\n"))
+	assert.Error(t, err)
+}
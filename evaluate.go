@@ -0,0 +1,251 @@
+package nlp
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type Accuracy struct {
+	Correct int
+	Total   int
+}
+
+func (a *Accuracy) add(correct bool) {
+	a.Total++
+	if correct {
+		a.Correct++
+	}
+}
+
+func (a Accuracy) Value() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+func (a Accuracy) String() string {
+	return fmt.Sprintf("%.2f%% (%d/%d)", 100*a.Value(), a.Correct, a.Total)
+}
+
+type EvalScores struct {
+	Lemma    Accuracy
+	UPOS     Accuracy
+	FEATS    Accuracy
+	Features map[string]*Accuracy
+}
+
+func newEvalScores() EvalScores {
+	scores := EvalScores{Features: map[string]*Accuracy{}}
+	for _, f := range evalFeatures {
+		scores.Features[f.name] = &Accuracy{}
+	}
+	return scores
+}
+
+type EvalOptions struct {
+	GoldTokenization bool
+}
+
+type EvalReport struct {
+	Sentences    int
+	GoldTokens   int
+	Aligned      int
+	All          EvalScores
+	InVocabulary EvalScores
+	OOV          EvalScores
+
+	UPOSConfusion    map[string]map[string]int
+	FeatureConfusion map[string]map[string]map[string]int
+}
+
+var evalFeatures = []struct {
+	name  string
+	value func(FEATS) string
+}{
+	{"Animacy", func(f FEATS) string { return f.Animacy().String() }},
+	{"Aspect", func(f FEATS) string { return f.Aspect().String() }},
+	{"Case", func(f FEATS) string { return f.Case().String() }},
+	{"Degree", func(f FEATS) string { return f.Degree().String() }},
+	{"Gender", func(f FEATS) string { return f.Gender().String() }},
+	{"Number", func(f FEATS) string { return f.Number().String() }},
+	{"Person", func(f FEATS) string { return f.Person().String() }},
+	{"Variant", func(f FEATS) string { return f.Variant().String() }},
+	{"VerbForm", func(f FEATS) string { return f.VerbForm().String() }},
+	{"Voice", func(f FEATS) string { return f.Voice().String() }},
+}
+
+func (l *Lemmatizer) Evaluate(gold []CoNLLUSentence, opts EvalOptions) *EvalReport {
+	report := &EvalReport{
+		All:              newEvalScores(),
+		InVocabulary:     newEvalScores(),
+		OOV:              newEvalScores(),
+		UPOSConfusion:    map[string]map[string]int{},
+		FeatureConfusion: map[string]map[string]map[string]int{},
+	}
+
+	for _, sentence := range gold {
+		report.Sentences++
+		report.GoldTokens += len(sentence.Tokens)
+
+		var predicted []AnalyzedToken
+		aligned := make([]int, len(sentence.Tokens))
+
+		if opts.GoldTokenization {
+			forms := make([]string, len(sentence.Tokens))
+			for i, t := range sentence.Tokens {
+				forms[i] = t.Form
+				aligned[i] = i
+			}
+			predicted = l.analyzeTokens(CreateTokens(forms), false)
+		} else {
+			var sb strings.Builder
+			type span struct{ start, end int }
+			spans := make([]span, len(sentence.Tokens))
+			for i, t := range sentence.Tokens {
+				spans[i].start = sb.Len()
+				sb.WriteString(t.Form)
+				spans[i].end = sb.Len()
+				if t.SpaceAfter() {
+					sb.WriteByte(' ')
+				}
+			}
+
+			predicted = l.analyzeTokens(Tokenize(sb.String(), l.keywords), false)
+			byStart := make(map[span]int, len(predicted))
+			for i, p := range predicted {
+				byStart[span{p.Start, p.End}] = i
+			}
+			for i, s := range spans {
+				if idx, ok := byStart[s]; ok {
+					aligned[i] = idx
+				} else {
+					aligned[i] = -1
+				}
+			}
+		}
+
+		for i, g := range sentence.Tokens {
+			if aligned[i] < 0 {
+				continue
+			}
+			report.Aligned++
+
+			if g.FEATS.POS() == PUNCT {
+				continue
+			}
+
+			p := predicted[aligned[i]]
+			oov := len(l.getForms(p.Text)) == 0
+			report.All.add(g, p)
+			if oov {
+				report.OOV.add(g, p)
+			} else {
+				report.InVocabulary.add(g, p)
+			}
+
+			addConfusion(report.UPOSConfusion, uposName(g.FEATS), uposName(p.FEATS))
+			for _, f := range evalFeatures {
+				if report.FeatureConfusion[f.name] == nil {
+					report.FeatureConfusion[f.name] = map[string]map[string]int{}
+				}
+				addConfusion(report.FeatureConfusion[f.name], featureValue(f.value(g.FEATS)), featureValue(f.value(p.FEATS)))
+			}
+		}
+	}
+
+	return report
+}
+
+func (s *EvalScores) add(gold CoNLLUToken, predicted AnalyzedToken) {
+	s.Lemma.add(Normalize(gold.Lemma) == predicted.Lemma)
+	s.UPOS.add(gold.FEATS.POS() == predicted.FEATS.POS())
+	s.FEATS.add(gold.FEATS.UDFeatures() == predicted.FEATS.UDFeatures())
+	for _, f := range evalFeatures {
+		s.Features[f.name].add(f.value(gold.FEATS) == f.value(predicted.FEATS))
+	}
+}
+
+func addConfusion(matrix map[string]map[string]int, gold, predicted string) {
+	if matrix[gold] == nil {
+		matrix[gold] = map[string]int{}
+	}
+	matrix[gold][predicted]++
+}
+
+func uposName(f FEATS) string {
+	if f.POS() == UNKNOWN {
+		return "X"
+	}
+	return f.POS().String()
+}
+
+func featureValue(s string) string {
+	if _, value, ok := strings.Cut(s, "="); ok {
+		return value
+	}
+	return "_"
+}
+
+func (r *EvalReport) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "sentences\t%d\n", r.Sentences)
+	fmt.Fprintf(tw, "gold tokens\t%d\n", r.GoldTokens)
+	fmt.Fprintf(tw, "aligned tokens\t%d\n\n", r.Aligned)
+
+	fmt.Fprintf(tw, "\tall\tin vocabulary\toov\n")
+	row := func(name string, get func(EvalScores) Accuracy) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, get(r.All), get(r.InVocabulary), get(r.OOV))
+	}
+	row("lemma", func(s EvalScores) Accuracy { return s.Lemma })
+	row("upos", func(s EvalScores) Accuracy { return s.UPOS })
+	row("feats", func(s EvalScores) Accuracy { return s.FEATS })
+	for _, f := range evalFeatures {
+		row(f.name, func(s EvalScores) Accuracy { return *s.Features[f.name] })
+	}
+	fmt.Fprintln(tw)
+
+	printConfusion(tw, "UPOS", r.UPOSConfusion)
+	for _, f := range evalFeatures {
+		printConfusion(tw, f.name, r.FeatureConfusion[f.name])
+	}
+
+	return tw.Flush()
+}
+
+func printConfusion(w io.Writer, name string, matrix map[string]map[string]int) {
+	labels := map[string]struct{}{}
+	for gold, row := range matrix {
+		labels[gold] = struct{}{}
+		for predicted := range row {
+			labels[predicted] = struct{}{}
+		}
+	}
+	if len(labels) == 0 {
+		return
+	}
+
+	sorted := make([]string, 0, len(labels))
+	for label := range labels {
+		sorted = append(sorted, label)
+	}
+	sort.Strings(sorted)
+
+	fmt.Fprintf(w, "%s gold\\pred", name)
+	for _, label := range sorted {
+		fmt.Fprintf(w, "\t%s", label)
+	}
+	fmt.Fprintln(w)
+	for _, gold := range sorted {
+		fmt.Fprint(w, gold)
+		for _, predicted := range sorted {
+			fmt.Fprintf(w, "\t%d", matrix[gold][predicted])
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,48 @@
+package nlp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const evalGold = "# text = Мама мыла раму.\n" +
+	"1\tМама\tмама\tNOUN\t_\tAnimacy=Anim|Case=Nom|Gender=Fem|Number=Sing\t_\t_\t_\t_\n" +
+	"2\tмыла\tмыть\tVERB\t_\tGender=Fem|Number=Sing|VerbForm=Fin\t_\t_\t_\t_\n" +
+	"3\tраму\tрама\tNOUN\t_\tCase=Acc|Gender=Fem|Number=Sing\t_\t_\t_\tSpaceAfter=No\n" +
+	"4\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n" +
+	"\n" +
+	"# text = Стали абырвалг.\n" +
+	"1\tСтали\tстать\tVERB\t_\tNumber=Plur|VerbForm=Fin\t_\t_\t_\t_\n" +
+	"2\tабырвалг\tабырвалг\tNOUN\t_\tCase=Nom\t_\t_\t_\tSpaceAfter=No\n" +
+	"3\t.\t.\tPUNCT\t_\t_\t_\t_\t_\t_\n" +
+	"\n"
+
+func TestEvaluate(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	gold, err := ReadCoNLLU(strings.NewReader(evalGold))
+	require.NoError(t, err)
+
+	for _, goldTokens := range []bool{false, true} {
+		report := l.Evaluate(gold, EvalOptions{GoldTokenization: goldTokens})
+
+		assert.Equal(t, 2, report.Sentences)
+		assert.Equal(t, 7, report.GoldTokens)
+		assert.Equal(t, 7, report.Aligned)
+		assert.Equal(t, Accuracy{Correct: 5, Total: 5}, report.All.Lemma)
+		assert.Equal(t, Accuracy{Correct: 4, Total: 4}, report.InVocabulary.UPOS)
+		assert.Equal(t, Accuracy{Correct: 1, Total: 1}, report.OOV.Lemma)
+		assert.Equal(t, Accuracy{Correct: 4, Total: 5}, *report.All.Features["Animacy"])
+		assert.Equal(t, Accuracy{Correct: 3, Total: 5}, report.All.FEATS)
+		assert.Equal(t, 2, report.UPOSConfusion["VERB"]["VERB"])
+		assert.Equal(t, 1, report.FeatureConfusion["Animacy"]["Anim"]["_"])
+
+		var buf bytes.Buffer
+		require.NoError(t, report.Print(&buf))
+		assert.Contains(t, buf.String(), "lemma")
+	}
+}
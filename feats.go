@@ -1,6 +1,9 @@
 package nlp
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 type POS uint8
 
@@ -25,6 +28,14 @@ const (
 	_END
 )
 
+// UPOS returns the Universal Dependencies tag of p, which writes UNKNOWN as X.
+func (p POS) UPOS() string {
+	if p == UNKNOWN {
+		return "X"
+	}
+	return p.String()
+}
+
 func (p POS) String() string {
 	switch p {
 	case UNKNOWN:
@@ -122,6 +133,20 @@ func (f FEATS) String() string {
 	return strings.Join(filtered, "|")
 }
 
+func (f FEATS) UDFeatures() string {
+	strs := []string{f.Case().String(), f.VerbForm().String(), f.Variant().String(),
+		f.Gender().String(), f.Person().String(), f.Number().String(), f.Degree().String(),
+		f.Animacy().String(), f.Aspect().String(), f.Voice().String()}
+	filtered := make([]string, 0, len(strs))
+	for _, s := range strs {
+		if len(s) > 0 {
+			filtered = append(filtered, s)
+		}
+	}
+	sort.Strings(filtered)
+	return strings.Join(filtered, "|")
+}
+
 func (f FEATS) VerbForm() VerbForm {
 	return VerbForm(f & VerbFormMask >> shiftVerbForm)
 }
@@ -188,6 +213,96 @@ func (f FEATS) SetAspect(a Aspect) FEATS { return setField(f, FEATS(a), shiftAsp
 func (f FEATS) SetVoice(v Voice) FEATS   { return setField(f, FEATS(v), shiftVoice, shortVoiceMask) }
 func (f FEATS) SetPOS(p POS) FEATS       { return setField(f, FEATS(p), shiftPOS, shortPOSMask) }
 
+var featureMasks = []FEATS{POSMask, VerbFormMask, VariantMask, DegreeMask, PersonMask, NumberMask,
+	GenderMask, CaseMask, AnimacyMask, AspectMask, VoiceMask}
+
+func (f FEATS) Matches(target FEATS) bool {
+	for _, mask := range featureMasks {
+		if target&mask != 0 && f&mask != target&mask {
+			return false
+		}
+	}
+	return true
+}
+
+var udFeatureValues = buildUDFeatureValues()
+
+func buildUDFeatureValues() map[string]FEATS {
+	values := map[string]FEATS{}
+	for v := Inf; v <= Conv; v++ {
+		values[v.String()] = FEATS(0).SetVerbForm(v)
+	}
+	for v := Full; v <= Short; v++ {
+		values[v.String()] = FEATS(0).SetVariant(v)
+	}
+	for d := Pos; d <= Sup; d++ {
+		values[d.String()] = FEATS(0).SetDegree(d)
+	}
+	for p := Person1; p <= Person3; p++ {
+		values[p.String()] = FEATS(0).SetPerson(p)
+	}
+	for n := Sing; n <= Plur; n++ {
+		values[n.String()] = FEATS(0).SetNumber(n)
+	}
+	for g := Neut; g <= Masc; g++ {
+		values[g.String()] = FEATS(0).SetGender(g)
+	}
+	for c := Nom; c <= Voc; c++ {
+		values[c.String()] = FEATS(0).SetCase(c)
+	}
+	for a := Inan; a <= Anim; a++ {
+		values[a.String()] = FEATS(0).SetAnimacy(a)
+	}
+	for a := Perf; a <= Imp; a++ {
+		values[a.String()] = FEATS(0).SetAspect(a)
+	}
+	for v := Act; v <= Pass; v++ {
+		values[v.String()] = FEATS(0).SetVoice(v)
+	}
+	return values
+}
+
+func parsePOS(s string) (POS, bool) {
+	if s == "X" {
+		return UNKNOWN, true
+	}
+	for p := UNKNOWN; p < _END; p++ {
+		if p.String() == s {
+			return p, true
+		}
+	}
+	return UNKNOWN, false
+}
+
+func (f FEATS) mask() FEATS {
+	var result FEATS
+	for _, mask := range featureMasks {
+		if f&mask != 0 {
+			result |= mask
+		}
+	}
+	return result
+}
+
+func ParseFEATS(s string) (FEATS, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "_" {
+		return 0, nil
+	}
+
+	upos, feats := "", s
+	if first, rest, _ := strings.Cut(s, "|"); !strings.Contains(first, "=") {
+		upos, feats = first, rest
+	}
+	result, _, err := parseUDColumns(upos, feats, true)
+	return result, err
+}
+
+func ParseUDFEATS(upos, feats string) (FEATS, error) {
+	result, _, err := parseUDColumns(strings.TrimSpace(upos), strings.TrimSpace(feats), true)
+	return result, err
+}
+
 const START_TAG FEATS = 0
 
 const SuperMask = POSMask | CaseMask | NumberMask | GenderMask | VerbFormMask | PersonMask | VoiceMask | AnimacyMask | AspectMask // | DegreeMask | VariantMask
@@ -275,11 +390,11 @@ func (p Person) String() string {
 	case 0:
 		return ""
 	case Person1:
-		return "Person=Person1"
+		return "Person=1"
 	case Person2:
-		return "Person=Person2"
+		return "Person=2"
 	case Person3:
-		return "Person=Person3"
+		return "Person=3"
 	}
 	return "Person=err"
 }
@@ -9,6 +9,8 @@ import (
 func TestString(t *testing.T) {
 	var feats FEATS
 	assert.Equal(t, "UNKNOWN", feats.String())
+	assert.Equal(t, "X", feats.POS().UPOS())
+	assert.Equal(t, "NOUN", NOUN.UPOS())
 
 	feats = feats.SetPOS(DET)
 	assert.Equal(t, "DET", feats.String())
@@ -17,29 +19,64 @@ func TestString(t *testing.T) {
 	assert.Equal(t, "DET|Number=Sing", feats.String())
 
 	feats = feats.SetPerson(Person2)
-	assert.Equal(t, "DET|Person=Person2|Number=Sing", feats.String())
+	assert.Equal(t, "DET|Person=2|Number=Sing", feats.String())
 
 	feats = feats.SetVoice(Pass)
-	assert.Equal(t, "DET|Person=Person2|Number=Sing|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|Person=2|Number=Sing|Voice=Pass", feats.String())
 
 	feats = feats.SetGender(Masc)
-	assert.Equal(t, "DET|Gender=Masc|Person=Person2|Number=Sing|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|Gender=Masc|Person=2|Number=Sing|Voice=Pass", feats.String())
 
 	feats = feats.SetVerbForm(Conv)
-	assert.Equal(t, "DET|VerbForm=Conv|Gender=Masc|Person=Person2|Number=Sing|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|VerbForm=Conv|Gender=Masc|Person=2|Number=Sing|Voice=Pass", feats.String())
 
 	feats = feats.SetVariant(Short)
-	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=Person2|Number=Sing|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=2|Number=Sing|Voice=Pass", feats.String())
 
 	feats = feats.SetAspect(Imp)
-	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=Person2|Number=Sing|Aspect=Imp|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=2|Number=Sing|Aspect=Imp|Voice=Pass", feats.String())
 
 	feats = feats.SetAnimacy(Anim)
-	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=Person2|Number=Sing|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=2|Number=Sing|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
 
 	feats = feats.SetDegree(Sup)
-	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=Person2|Number=Sing|Degree=Sup|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|VerbForm=Conv|Variant=Short|Gender=Masc|Person=2|Number=Sing|Degree=Sup|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
 
 	feats = feats.SetCase(Voc)
-	assert.Equal(t, "DET|Case=Voc|VerbForm=Conv|Variant=Short|Gender=Masc|Person=Person2|Number=Sing|Degree=Sup|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
+	assert.Equal(t, "DET|Case=Voc|VerbForm=Conv|Variant=Short|Gender=Masc|Person=2|Number=Sing|Degree=Sup|Animacy=Anim|Aspect=Imp|Voice=Pass", feats.String())
+}
+
+func TestParseFEATS(t *testing.T) {
+	feats, err := ParseFEATS("NOUN|Case=Gen|Number=Plur")
+	assert.NoError(t, err)
+	assert.Equal(t, FEATS(0).SetPOS(NOUN).SetCase(Gen).SetNumber(Plur), feats)
+
+	feats, err = ParseFEATS("Number=Plur|Case=Gen")
+	assert.NoError(t, err)
+	assert.Equal(t, FEATS(0).SetCase(Gen).SetNumber(Plur), feats)
+
+	feats, err = ParseUDFEATS("VERB", "Aspect=Imp|Person=3|VerbForm=Fin")
+	assert.NoError(t, err)
+	assert.Equal(t, FEATS(0).SetPOS(VERB).SetAspect(Imp).SetPerson(Person3).SetVerbForm(Fin), feats)
+
+	feats, err = ParseUDFEATS("X", "_")
+	assert.NoError(t, err)
+	assert.Equal(t, FEATS(0), feats)
+
+	all := FEATS(0).SetPOS(DET).SetCase(Voc).SetVerbForm(Conv).SetVariant(Short).SetGender(Masc).SetPerson(Person2).
+		SetNumber(Sing).SetDegree(Sup).SetAnimacy(Anim).SetAspect(Imp).SetVoice(Pass)
+	for _, f := range []FEATS{0, FEATS(0).SetPOS(ADV), all} {
+		parsed, err := ParseFEATS(f.String())
+		assert.NoError(t, err)
+		assert.Equal(t, f, parsed)
+	}
+
+	_, err = ParseFEATS("NOUN|Tense=Past")
+	assert.Error(t, err)
+	_, err = ParseFEATS("NOUN|Case=Abl")
+	assert.Error(t, err)
+	_, err = ParseFEATS("NOUN|Case=Gen|Case=Nom")
+	assert.Error(t, err)
+	_, err = ParseUDFEATS("NOUNS", "")
+	assert.Error(t, err)
 }
@@ -0,0 +1,235 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixtureForm struct {
+	form  string
+	lemma string
+	feats FEATS
+	count uint16
+}
+
+type fixtureLink struct {
+	from     string
+	to       string
+	linkType string
+}
+
+func noun(g Gender, c Case, n Number) FEATS {
+	return FEATS(0).SetPOS(NOUN).SetGender(g).SetCase(c).SetNumber(n)
+}
+
+func adj(g Gender, c Case, n Number) FEATS {
+	return FEATS(0).SetPOS(ADJ).SetGender(g).SetCase(c).SetNumber(n).SetVariant(Full).SetDegree(Pos)
+}
+
+func verb(vf VerbForm, g Gender, n Number) FEATS {
+	return FEATS(0).SetPOS(VERB).SetVerbForm(vf).SetGender(g).SetNumber(n)
+}
+
+var fixtureForms = []fixtureForm{
+	{"в", "в", FEATS(0).SetPOS(ADP), 900},
+	{"и", "и", FEATS(0).SetPOS(CCONJ), 1000},
+	{"он", "он", FEATS(0).SetPOS(PRON).SetCase(Nom).SetNumber(Sing).SetGender(Masc).SetPerson(Person3), 300},
+	{"мама", "мама", noun(Fem, Nom, Sing), 40},
+	{"маму", "мама", noun(Fem, Acc, Sing), 10},
+	{"мыла", "мыть", verb(Fin, Fem, Sing), 20},
+	{"мыла", "мыло", noun(Neut, Gen, Sing), 5},
+	{"мыло", "мыло", noun(Neut, Nom, Sing), 10},
+	{"мыть", "мыть", verb(Inf, 0, 0), 10},
+	{"раму", "рама", noun(Fem, Acc, Sing), 10},
+	{"рама", "рама", noun(Fem, Nom, Sing), 10},
+	{"стали", "стать", verb(Fin, 0, Plur), 60},
+	{"стали", "сталь", noun(Fem, Gen, Sing), 15},
+	{"стали", "сталь", noun(Fem, Dat, Sing), 2},
+	{"стали", "сталь", noun(Fem, Loc, Sing), 8},
+	{"стали", "сталь", noun(Fem, Nom, Plur), 1},
+	{"сталь", "сталь", noun(Fem, Nom, Sing), 20},
+	{"сталь", "сталь", noun(Fem, Acc, Sing), 10},
+	{"стать", "стать", verb(Inf, 0, 0), 30},
+	{"москва", "москва", noun(Fem, Nom, Sing), 50},
+	{"москвы", "москва", noun(Fem, Gen, Sing), 30},
+	{"москве", "москва", noun(Fem, Dat, Sing), 5},
+	{"москве", "москва", noun(Fem, Loc, Sing), 40},
+	{"москву", "москва", noun(Fem, Acc, Sing), 20},
+	{"москвой", "москва", noun(Fem, Ins, Sing), 10},
+	{"дом", "дом", noun(Masc, Nom, Sing), 50},
+	{"дом", "дом", noun(Masc, Acc, Sing), 30},
+	{"дома", "дом", noun(Masc, Gen, Sing), 40},
+	{"дома", "дом", noun(Masc, Nom, Plur), 20},
+	{"доме", "дом", noun(Masc, Loc, Sing), 30},
+	{"домов", "дом", noun(Masc, Gen, Plur), 10},
+	{"файл", "файл", noun(Masc, Nom, Sing), 20},
+	{"файл", "файл", noun(Masc, Acc, Sing), 20},
+	{"файла", "файл", noun(Masc, Gen, Sing), 15},
+	{"файлы", "файл", noun(Masc, Nom, Plur), 10},
+	{"файлов", "файл", noun(Masc, Gen, Plur), 10},
+	{"файле", "файл", noun(Masc, Loc, Sing), 5},
+	{"книга", "книга", noun(Fem, Nom, Sing), 20},
+	{"книги", "книга", noun(Fem, Gen, Sing), 15},
+	{"книги", "книга", noun(Fem, Nom, Plur), 10},
+	{"книг", "книга", noun(Fem, Gen, Plur), 8},
+	{"новый", "новый", adj(Masc, Nom, Sing), 30},
+	{"новый", "новый", adj(Masc, Acc, Sing), 10},
+	{"нового", "новый", adj(Masc, Gen, Sing), 10},
+	{"нового", "новый", adj(Neut, Gen, Sing), 5},
+	{"новом", "новый", adj(Masc, Loc, Sing), 10},
+	{"новом", "новый", adj(Neut, Loc, Sing), 5},
+	{"новая", "новый", adj(Fem, Nom, Sing), 20},
+	{"новой", "новый", adj(Fem, Gen, Sing), 10},
+	{"новой", "новый", adj(Fem, Loc, Sing), 10},
+	{"новое", "новый", adj(Neut, Nom, Sing), 10},
+	{"новые", "новый", adj(0, Nom, Plur), 10},
+	{"новых", "новый", adj(0, Gen, Plur), 10},
+	{"новых", "новый", adj(0, Loc, Plur), 5},
+	{"читать", "читать", verb(Inf, 0, 0), 20},
+	{"читает", "читать", verb(Fin, 0, Sing).SetPerson(Person3), 20},
+	{"читающий", "читающий", FEATS(0).SetPOS(ADJ).SetVerbForm(Part).SetGender(Masc).SetCase(Nom).SetNumber(Sing), 5},
+	{"быстрый", "быстрый", adj(Masc, Nom, Sing), 10},
+	{"быстрее", "быстрее", FEATS(0).SetPOS(ADJ).SetDegree(Cmp), 10},
+}
+
+var fixtureLinks = []fixtureLink{
+	{from: "читать", to: "читающий", linkType: "INFN-PRTF"},
+	{from: "быстрый", to: "быстрее", linkType: "ADJF-COMP"},
+}
+
+var fixtureSentences = [][]string{
+	{"в", "новом", "доме"},
+	{"в", "москве"},
+	{"мама", "мыла", "раму"},
+	{"он", "читает", "книги"},
+	{"новый", "файл"},
+	{"стали", "новый", "дом"},
+}
+
+func buildFixtureData(forms []fixtureForm, links []fixtureLink, sentences [][]string) LemmatizerData {
+	var dict DictionaryBase
+	dict.LinkTypes = map[string]LinkType{}
+	for i, name := range []string{"ADJF-ADJS", "ADJF-COMP", "INFN-VERB", "INFN-PRTF", "INFN-GRND", "PRTF-PRTS",
+		"ADJF-SUPR_ejsh", "ADJF-SUPR_ajsh", "ADJF-SUPR_suppl", "ADJF-SUPR_nai", "ADJF-SUPR_slng", "NORM-ORPHOVAR",
+		"SBST_MASC-SBST_FEMN", "SBST_MASC-SBST_PLUR", "ADVB-COMP"} {
+		dict.LinkTypes[name] = LinkType(i + 1)
+	}
+
+	texts := map[string]uint32{}
+	addText := func(s string) uint32 {
+		if start, ok := texts[s]; ok {
+			return start
+		}
+		texts[s] = uint32(len(dict.Texts))
+		dict.Texts += s
+		return texts[s]
+	}
+
+	lemmaIdx := map[string]uint32{}
+	dict.Lemmas = []Lemma{{}}
+	for _, f := range forms {
+		if _, ok := lemmaIdx[f.lemma]; ok {
+			continue
+		}
+		lemmaIdx[f.lemma] = uint32(len(dict.Lemmas))
+		dict.Lemmas = append(dict.Lemmas, Lemma{
+			TextStart: addText(f.lemma),
+			TextLen:   uint8(len(f.lemma)),
+			FEATS:     f.feats & (POSMask | GenderMask),
+		})
+	}
+	for _, f := range forms {
+		lemma := &dict.Lemmas[lemmaIdx[f.lemma]]
+		lemma.CountTotal += f.count
+		lemma.CountDocs += f.count / 2
+	}
+
+	for i := range dict.Lemmas {
+		text := dict.lemmaText(dict.Lemmas[i])
+		dict.Lemmas[i].LinkIdx = uint32(len(dict.Links))
+		for _, link := range links {
+			if link.to == text {
+				dict.Links = append(dict.Links, Link{FromLemmaIdx: lemmaIdx[link.from], Type: dict.LinkTypes[link.linkType]})
+				dict.Lemmas[i].LinkLen++
+			}
+		}
+	}
+
+	for i, f := range forms {
+		if i > 0 && forms[i-1].form == f.form {
+			dict.FormTexts[len(dict.FormTexts)-1].FormLen++
+		} else {
+			dict.FormTexts = append(dict.FormTexts, FormText{
+				TextStart: addText(f.form),
+				TextLen:   uint8(len(f.form)),
+				FormIdx:   uint32(len(dict.Forms)),
+				FormLen:   1,
+			})
+		}
+		dict.Forms = append(dict.Forms, Form{LemmaIdx: lemmaIdx[f.lemma], FEATS: f.feats, CountTotal: f.count, CountDocs: f.count / 2})
+	}
+	dict.BuildFormTextIndex()
+
+	tagger := StatisticalTagger{
+		TransitionCounts: map[FEATS]map[FEATS]int{},
+		TagTotalCounts:   map[FEATS]int{},
+		UniqueWords:      len(dict.FormTexts),
+	}
+	for _, f := range forms {
+		tagger.TagTotalCounts[f.feats&BigramMask] += int(f.count)
+	}
+	bestTag := func(word string) FEATS {
+		best := fixtureForm{}
+		for _, f := range forms {
+			if f.form == word && f.count > best.count {
+				best = f
+			}
+		}
+		return best.feats
+	}
+	for _, sentence := range sentences {
+		prev := startTag & BigramMask
+		tagger.TagTotalCounts[prev]++
+		for _, word := range sentence {
+			tag := bestTag(word) & BigramMask
+			if tagger.TransitionCounts[prev] == nil {
+				tagger.TransitionCounts[prev] = map[FEATS]int{}
+			}
+			tagger.TransitionCounts[prev][tag] += 10
+			prev = tag
+		}
+	}
+	tagger.UniqueTags = len(tagger.TagTotalCounts)
+	dict.Tagger = tagger
+
+	return LemmatizerData{
+		Dictionary:      dict,
+		SuffixPredictor: SuffixPredictorBase{NodePool: []SuffixNode{{}}},
+	}
+}
+
+func newTestLemmatizer(t testing.TB) *Lemmatizer {
+	t.Helper()
+
+	l, err := NewLemmatizer(buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences))
+	require.NoError(t, err)
+	return l
+}
+
+// suffixFixtureData adds a suffix model trained on the fixture dictionary.
+func suffixFixtureData() LemmatizerData {
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+	b := NewSuffixPredictorBuilder()
+	b.AddDictionary(&data.Dictionary)
+	data.SuffixPredictor = b.Build()
+	return data
+}
+
+func newSuffixLemmatizer(t testing.TB) *Lemmatizer {
+	t.Helper()
+
+	l, err := NewLemmatizer(suffixFixtureData())
+	require.NoError(t, err)
+	return l
+}
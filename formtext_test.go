@@ -0,0 +1,95 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collideFormTexts rebuilds the form text index so that the hash of every form
+// text, and of each unknown word, first resolves to other form texts and the
+// right one is only found at the end of its chain.
+func collideFormTexts(dict *DictionaryBase, unknown ...string) {
+	n := uint32(len(dict.FormTexts))
+	dict.FormTextIndex = map[uint64]uint32{}
+	dict.FormTextChains = map[uint64][]uint32{}
+
+	for i, ft := range dict.FormTexts {
+		hash := formTextHash(dict.formText(ft))
+		dict.FormTextIndex[hash] = (uint32(i) + 1) % n
+		dict.FormTextChains[hash] = []uint32{(uint32(i) + 2) % n, uint32(i)}
+	}
+	for _, word := range unknown {
+		hash := formTextHash(word)
+		dict.FormTextIndex[hash] = 0
+		dict.FormTextChains[hash] = []uint32{1, 2}
+	}
+}
+
+func TestBuildFormTextIndexChains(t *testing.T) {
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+	dict := &data.Dictionary
+	n := len(dict.FormTexts)
+
+	// A second entry with the same text shares its hash under xxhash.
+	text := dict.formText(dict.FormTexts[0])
+	dict.FormTexts = append(dict.FormTexts, dict.FormTexts[0])
+	dict.BuildFormTextIndex()
+	assert.Equal(t, uint32(0), dict.FormTextIndex[formTextHash(text)])
+	assert.Equal(t, []uint32{uint32(n)}, dict.FormTextChains[formTextHash(text)])
+	idx, ok := dict.lookupFormText(text)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0), idx)
+
+	// Hashing by length makes different texts collide.
+	lengthHash := func(s string) uint64 { return uint64(len(s)) }
+	dict.FormTexts = dict.FormTexts[:n]
+	dict.buildFormTextIndex(lengthHash)
+	require.NotEmpty(t, dict.FormTextChains)
+	for i, ft := range dict.FormTexts {
+		text := dict.formText(ft)
+		idx, ok := dict.lookupFormTextHash(text, lengthHash(text))
+		assert.True(t, ok, text)
+		assert.Equal(t, uint32(i), idx, text)
+	}
+	_, ok = dict.lookupFormTextHash("абырвалг", lengthHash("абырвалг"))
+	assert.False(t, ok)
+}
+
+func TestGetFormsVerifiesText(t *testing.T) {
+	l := newSuffixLemmatizer(t)
+	dict := &l.base.Dictionary
+
+	idx, ok := dict.lookupFormText("мама")
+	require.True(t, ok)
+	dict.FormTextIndex[formTextHash("сталями")] = idx
+
+	assert.Nil(t, l.getForms("сталями"))
+	parses := l.Analyze("сталями")
+	require.NotEmpty(t, parses)
+	assert.Equal(t, SourceSuffixPredictor, parses[0].Source)
+	assert.NotEqual(t, "мама", l.LemmatizeWord("сталями"))
+}
+
+func TestFormTextChains(t *testing.T) {
+	l := newTestLemmatizer(t)
+	expected := map[string][]Form{}
+	for _, f := range fixtureForms {
+		expected[f.form] = l.getForms(f.form)
+	}
+
+	l = newSuffixLemmatizer(t)
+	collideFormTexts(&l.base.Dictionary, "мамы", "раме", "домам", "абырвалг")
+
+	for form, forms := range expected {
+		assert.Equal(t, forms, l.getForms(form), form)
+	}
+
+	for _, word := range []string{"мамы", "раме", "домам", "абырвалг"} {
+		assert.Nil(t, l.getForms(word), word)
+		parses := l.Analyze(word)
+		require.NotEmpty(t, parses, word)
+		assert.Equal(t, SourceSuffixPredictor, parses[0].Source, word)
+	}
+}
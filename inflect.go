@@ -0,0 +1,134 @@
+package nlp
+
+import "sort"
+
+type lemmaForm struct {
+	FormIdx     uint32
+	FormTextIdx uint32
+}
+
+type FormWithFEATS struct {
+	Text       string
+	FEATS      FEATS
+	CountTotal uint16
+}
+
+func (d *DictionaryBase) buildLemmaIndex() {
+	if d.lemmaFormsStart == nil {
+		d.buildLemmaForms()
+	}
+	d.buildDerived()
+}
+
+func (d *DictionaryBase) buildLemmaForms() {
+	d.lemmaFormsStart = make([]uint32, len(d.Lemmas)+1)
+	for _, ft := range d.FormTexts {
+		for i := range ft.FormLen {
+			d.lemmaFormsStart[d.Forms[ft.FormIdx+uint32(i)].LemmaIdx+1]++
+		}
+	}
+	for i := 1; i < len(d.lemmaFormsStart); i++ {
+		d.lemmaFormsStart[i] += d.lemmaFormsStart[i-1]
+	}
+
+	d.lemmaForms = make([]lemmaForm, d.lemmaFormsStart[len(d.Lemmas)])
+	next := append([]uint32(nil), d.lemmaFormsStart[:len(d.Lemmas)]...)
+	for ti, ft := range d.FormTexts {
+		for i := range ft.FormLen {
+			formIdx := ft.FormIdx + uint32(i)
+			lemmaIdx := d.Forms[formIdx].LemmaIdx
+			d.lemmaForms[next[lemmaIdx]] = lemmaForm{FormIdx: formIdx, FormTextIdx: uint32(ti)}
+			next[lemmaIdx]++
+		}
+	}
+}
+
+func (d *DictionaryBase) buildDerived() {
+	d.derivedStart = make([]uint32, len(d.Lemmas)+1)
+	for _, link := range d.Links {
+		if d.importantLinks[link.Type] {
+			d.derivedStart[link.FromLemmaIdx+1]++
+		}
+	}
+	for i := 1; i < len(d.derivedStart); i++ {
+		d.derivedStart[i] += d.derivedStart[i-1]
+	}
+
+	d.derived = make([]uint32, d.derivedStart[len(d.Lemmas)])
+	next := append([]uint32(nil), d.derivedStart[:len(d.Lemmas)]...)
+	for lemmaIdx, lemma := range d.Lemmas {
+		for i := range lemma.LinkLen {
+			link := d.Links[lemma.LinkIdx+uint32(i)]
+			if d.importantLinks[link.Type] {
+				d.derived[next[link.FromLemmaIdx]] = uint32(lemmaIdx)
+				next[link.FromLemmaIdx]++
+			}
+		}
+	}
+}
+
+func (d *DictionaryBase) formText(ft FormText) string {
+	return d.Texts[ft.TextStart : ft.TextStart+uint32(ft.TextLen)]
+}
+
+func (l *Lemmatizer) Lexeme(lemma string) []FormWithFEATS {
+	lemma = Normalize(lemma)
+	dict := &l.base.Dictionary
+
+	var result []FormWithFEATS
+	visited := map[uint32]bool{}
+
+	var collect func(lemmaIdx uint32)
+	collect = func(lemmaIdx uint32) {
+		if visited[lemmaIdx] {
+			return
+		}
+		visited[lemmaIdx] = true
+
+		for _, lf := range dict.lemmaForms[dict.lemmaFormsStart[lemmaIdx]:dict.lemmaFormsStart[lemmaIdx+1]] {
+			form := dict.Forms[lf.FormIdx]
+			result = append(result, FormWithFEATS{
+				Text:       dict.exported(dict.formText(dict.FormTexts[lf.FormTextIdx])),
+				FEATS:      form.FEATS,
+				CountTotal: form.CountTotal,
+			})
+		}
+		for _, derivedIdx := range dict.derived[dict.derivedStart[lemmaIdx]:dict.derivedStart[lemmaIdx+1]] {
+			collect(derivedIdx)
+		}
+	}
+
+	for _, f := range l.dictForms(lemma) {
+		if f.LemmaIdx != 0 && dict.lemmaText(dict.Lemmas[f.LemmaIdx]) == lemma {
+			collect(f.LemmaIdx)
+		}
+	}
+	if l.user != nil {
+		result = append(result, l.user.lexeme(lemma)...)
+	}
+
+	return result
+}
+
+func (l *Lemmatizer) Inflect(lemma string, target FEATS) []string {
+	var matched []FormWithFEATS
+	for _, f := range l.Lexeme(lemma) {
+		if f.FEATS.Matches(target) {
+			matched = append(matched, f)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CountTotal > matched[j].CountTotal
+	})
+
+	var result []string
+	seen := map[string]bool{}
+	for _, f := range matched {
+		if !seen[f.Text] {
+			seen[f.Text] = true
+			result = append(result, f.Text)
+		}
+	}
+
+	return result
+}
@@ -12,6 +12,7 @@ var DefaultKeywords = KeywordSet{
 type Keywords struct {
 	keywords        map[string]struct{}
 	keywordPrefixes map[string]struct{}
+	maxLen          int
 }
 
 func NewKeywords(keywordSets ...KeywordSet) *Keywords {
@@ -23,6 +24,7 @@ func NewKeywords(keywordSets ...KeywordSet) *Keywords {
 	for _, set := range keywordSets {
 		for _, kw := range set {
 			k.keywords[kw] = struct{}{}
+			k.maxLen = max(k.maxLen, len(kw))
 			for i, c := range kw {
 				k.keywordPrefixes[kw[0:i+utf8.RuneLen(c)]] = struct{}{}
 			}
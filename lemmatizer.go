@@ -1,8 +1,12 @@
 package nlp
 
 import (
+	"encoding/gob"
 	"fmt"
 	"math"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/cespare/xxhash/v2"
 )
@@ -17,6 +21,8 @@ type FormText struct {
 }
 
 type Form struct {
+	// LemmaIdx indexes DictionaryBase.Lemmas, or the user dictionary when
+	// userLemmaFlag is set; resolve it with Lemmatizer.FormLemma.
 	LemmaIdx   uint32
 	FEATS      FEATS
 	CountTotal uint16
@@ -38,12 +44,21 @@ type Link struct {
 	Type         LinkType
 }
 
+type TagPair struct {
+	Prev2 FEATS
+	Prev  FEATS
+}
+
 type StatisticalTagger struct {
 	TransitionCounts map[FEATS]map[FEATS]int
 	TagTotalCounts   map[FEATS]int
 	UniqueWords      int
 	UniqueTags       int
 	Alpha            float64
+
+	TrigramCounts  map[TagPair]map[FEATS]int
+	TagPairCounts  map[TagPair]int
+	TrigramLambdas [3]float64
 }
 
 type DictionaryBase struct {
@@ -57,10 +72,34 @@ type DictionaryBase struct {
 	Links     []Link
 
 	FormTextIndex map[uint64]uint32
+	// FormTextChains lists the other FormTexts whose text hashes to the same
+	// value as the one stored in FormTextIndex.
+	FormTextChains map[uint64][]uint32
 
 	Tagger StatisticalTagger
 
 	importantLinks map[LinkType]bool
+
+	formIndex []formIndexEntry
+	mapped    bool
+
+	lemmaFormsStart []uint32
+	lemmaForms      []lemmaForm
+	derivedStart    []uint32
+	derived         []uint32
+}
+
+func (d *DictionaryBase) lemmaText(lemma Lemma) string {
+	return d.Texts[lemma.TextStart : lemma.TextStart+uint32(lemma.TextLen)]
+}
+
+// exported copies a string cut from Texts when Texts lives in a mapping, so
+// that results handed to callers outlive MappedData.Close.
+func (d *DictionaryBase) exported(s string) string {
+	if d.mapped {
+		return strings.Clone(s)
+	}
+	return s
 }
 
 type LemmaRule struct {
@@ -74,31 +113,145 @@ type LemmatizerData struct {
 	SuffixPredictor SuffixPredictorBase
 }
 
+// Lemmatizer is read-only once NewLemmatizer returns and is safe for concurrent
+// use by multiple goroutines.
 type Lemmatizer struct {
-	base     LemmatizerData
-	keywords *Keywords
+	base      LemmatizerData
+	keywords  *Keywords
+	stopwords *Stopwords
+	scores    *scoreCache
+	user      *UserDictionary
+
+	importantLinks []string
+	linkNames      map[LinkType]string
+	alpha          float64
+
+	trigram      bool
+	unigramTotal int
 }
 
-func NewLemmatizer(data LemmatizerData) (*Lemmatizer, error) {
+type Option func(*Lemmatizer) error
+
+func WithTrigramTagger() Option {
+	return func(l *Lemmatizer) error {
+		tagger := &l.base.Dictionary.Tagger
+		if len(tagger.TrigramCounts) == 0 {
+			return fmt.Errorf("tagger has no trigram counts")
+		}
+		// The unigram and bigram estimates are smoothed and never zero, so a
+		// positive weight on either keeps every trigram score finite.
+		lambdas := tagger.TrigramLambdas
+		if !(lambdas[0] >= 0 && lambdas[1] >= 0 && lambdas[2] >= 0) || !(lambdas[0]+lambdas[1] > 0) {
+			return fmt.Errorf("trigram lambdas %v must be non-negative with a positive unigram or bigram weight", lambdas)
+		}
+		l.trigram = true
+		l.unigramTotal = 0
+		for tag, count := range l.base.Dictionary.Tagger.TagTotalCounts {
+			if tag != startTag&BigramMask {
+				l.unigramTotal += count
+			}
+		}
+		return nil
+	}
+}
+
+// DefaultImportantLinks lists the link types NewLemmatizer follows from a
+// form's lemma to its normal form unless WithImportantLinks or WithProfile is
+// given.
+var DefaultImportantLinks = []string{"ADJF-ADJS", "ADJF-COMP", "INFN-VERB", "INFN-PRTF", "INFN-GRND", "PRTF-PRTS",
+	"ADJF-SUPR_ejsh", "ADJF-SUPR_ajsh", "ADJF-SUPR_suppl", "ADJF-SUPR_nai", "ADJF-SUPR_slng", "NORM-ORPHOVAR",
+	"SBST_MASC-SBST_FEMN", "SBST_MASC-SBST_PLUR", "ADVB-COMP"}
+
+// WithKeywords replaces DefaultKeywords in the text methods of the Lemmatizer.
+func WithKeywords(keywords *Keywords) Option {
+	return func(l *Lemmatizer) error {
+		if keywords == nil {
+			return fmt.Errorf("nil keywords")
+		}
+		l.keywords = keywords
+		return nil
+	}
+}
+
+// WithStopwords drops stopwords from the results of AnalyzeText, AnalyzeTokens
+// and AnalyzeSentences. LemmatizeText and LemmatizeTokens are not affected and
+// keep returning one lemma per token.
+func WithStopwords(stopwords *Stopwords) Option {
+	return func(l *Lemmatizer) error {
+		l.stopwords = stopwords
+		return nil
+	}
+}
+
+// WithImportantLinks replaces DefaultImportantLinks. Every name must be present
+// in the LinkTypes of the dictionary.
+func WithImportantLinks(linkTypes ...string) Option {
+	return func(l *Lemmatizer) error {
+		l.importantLinks = linkTypes
+		return nil
+	}
+}
+
+// WithSmoothing sets the additive smoothing of the tagger probabilities.
+func WithSmoothing(alpha float64) Option {
+	return func(l *Lemmatizer) error {
+		if !(alpha > 0) || math.IsInf(alpha, 1) {
+			return fmt.Errorf("smoothing must be positive, got %v", alpha)
+		}
+		l.alpha = alpha
+		return nil
+	}
+}
+
+// WithSuffixPredictor replaces the suffix predictor of the data.
+func WithSuffixPredictor(p SuffixPredictorBase) Option {
+	return func(l *Lemmatizer) error {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("suffix predictor: %w", err)
+		}
+		l.base.SuffixPredictor = p
+		return nil
+	}
+}
+
+func NewLemmatizer(data LemmatizerData, opts ...Option) (*Lemmatizer, error) {
 	l := Lemmatizer{
-		base:     data,
-		keywords: NewKeywords(DefaultKeywords),
+		base:           data,
+		keywords:       NewKeywords(DefaultKeywords),
+		importantLinks: DefaultImportantLinks,
+		alpha:          defaultAlpha,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&l); err != nil {
+			return nil, err
+		}
+	}
+
+	l.linkNames = make(map[LinkType]string, len(l.base.Dictionary.LinkTypes))
+	for name, id := range l.base.Dictionary.LinkTypes {
+		l.linkNames[id] = name
 	}
 
 	l.base.Dictionary.importantLinks = map[LinkType]bool{}
-	for _, typeText := range []string{"ADJF-ADJS", "ADJF-COMP", "INFN-VERB", "INFN-PRTF", "INFN-GRND", "PRTF-PRTS",
-		"ADJF-SUPR_ejsh", "ADJF-SUPR_ajsh", "ADJF-SUPR_suppl", "ADJF-SUPR_nai", "ADJF-SUPR_slng", "NORM-ORPHOVAR",
-		"SBST_MASC-SBST_FEMN", "SBST_MASC-SBST_PLUR", "ADVB-COMP"} {
-		if id, ok := l.base.Dictionary.LinkTypes[typeText]; ok {
-			l.base.Dictionary.importantLinks[id] = true
-		} else {
-			panic(fmt.Errorf("not found link type %s", typeText))
+	for _, typeText := range l.importantLinks {
+		id, ok := l.base.Dictionary.LinkTypes[typeText]
+		if !ok {
+			return nil, fmt.Errorf("link type %s not found in dictionary", typeText)
 		}
+		l.base.Dictionary.importantLinks[id] = true
 	}
+	l.base.Dictionary.buildLemmaIndex()
+	l.scores = newScoreCache(&l.base.Dictionary.Tagger, l.alpha)
 
 	return &l, nil
 }
 
+// Keywords returns the keywords the Lemmatizer tokenizes text with.
+func (l *Lemmatizer) Keywords() *Keywords {
+	return l.keywords
+}
+
 type Word struct {
 	Text    string
 	TokenID int
@@ -107,105 +260,64 @@ type Word struct {
 }
 
 func (l *Lemmatizer) GetLogScore(prevTag, currentTag FEATS, currentWord Word) float64 {
-	tagger := l.base.Dictionary.Tagger
-	tagger.Alpha = 0.25
-	transCount := tagger.TransitionCounts[prevTag&BigramMask][currentTag&BigramMask]
-	transDenom := tagger.TagTotalCounts[prevTag&BigramMask] + int(tagger.Alpha*float64(tagger.UniqueTags))
-	probTrans := (float64(transCount) + tagger.Alpha) / float64(transDenom)
+	return l.transitionLogProb(prevTag, currentTag)*transitionCoeff(prevTag, currentTag) + l.emissionLogProb(currentTag, currentWord)
+}
+
+const defaultAlpha = 0.25
+
+func (l *Lemmatizer) transitionLogProb(prevTag, currentTag FEATS) float64 {
+	return l.scores.transition(l.scores.tagIndex(prevTag), l.scores.tagIndex(currentTag))
+}
 
+func (l *Lemmatizer) emissionLogProb(currentTag FEATS, currentWord Word) float64 {
 	wordCount := 0
 	for _, f := range currentWord.Options {
 		if f.FEATS&BigramMask == currentTag&BigramMask {
 			wordCount += int(f.CountTotal)
 		}
 	}
+	return l.scores.emission(currentTag, wordCount)
+}
 
-	coeff := 1.0
+func transitionCoeff(prevTag, currentTag FEATS) float64 {
 	if prevTag.POS() == VERB && currentTag.POS() == NOUN {
 		if currentTag.Case() == Par || currentTag.Case() == Acc {
-			coeff = 0.85
+			return 0.85
 		}
 	}
-
-	wordDenom := tagger.TagTotalCounts[currentTag&BigramMask] + int(tagger.Alpha*float64(tagger.UniqueWords))
-	probEmission := (float64(wordCount) + tagger.Alpha) / float64(wordDenom)
-
-	return math.Log(probTrans)*coeff + math.Log(probEmission)
+	return 1.0
 }
 
-type ViterbiStep struct {
-	LogProb float64
-	BackPtr Form
+const startTag = FEATS(math.MaxInt32)
 
-	Text string
+func (l *Lemmatizer) Disambiguate(tokens []Token) []Word {
+	words := l.Candidates(tokens)
+	l.resolve(words)
+	return words
 }
 
-func (l *Lemmatizer) Viterbi(sentence []Word) []Form {
-	n := len(sentence)
-	if n == 0 {
-		return nil
-	}
-
-	dp := make([]map[Form]ViterbiStep, n)
-	for i := range dp {
-		dp[i] = make(map[Form]ViterbiStep)
-	}
-
-	dict := l.base.Dictionary
-	firstWord := sentence[0]
-	for _, form := range firstWord.Options {
-		score := l.GetLogScore(FEATS(math.MaxInt32), form.FEATS, firstWord)
-		dp[0][form] = ViterbiStep{LogProb: score, BackPtr: Form{FEATS: FEATS(math.MaxInt32)},
-			Text: dict.Texts[dict.Lemmas[form.LemmaIdx].TextStart : dict.Lemmas[form.LemmaIdx].TextStart+uint32(dict.Lemmas[form.LemmaIdx].TextLen)]}
-	}
-
-	for i := 1; i < n; i++ {
-		currWord := sentence[i]
-		prevWordForm := sentence[i-1].Options
-
-		for _, currForm := range currWord.Options {
-			bestLogProb := -math.MaxFloat64
-			bestPrevForm := Form{}
-
-			for _, prevForm := range prevWordForm {
-				prevStep, ok := dp[i-1][prevForm]
-				if !ok {
-					continue
-				}
-
-				score := prevStep.LogProb + l.GetLogScore(prevForm.FEATS, currForm.FEATS, currWord)
+func (l *Lemmatizer) DisambiguateSentences(tokens []Token) [][]Word {
+	words := l.Candidates(tokens)
 
-				if score > bestLogProb {
-					bestLogProb = score
-					bestPrevForm = prevForm
-				}
-			}
-			dp[i][currForm] = ViterbiStep{LogProb: bestLogProb, BackPtr: bestPrevForm,
-				Text: dict.Texts[dict.Lemmas[currForm.LemmaIdx].TextStart : dict.Lemmas[currForm.LemmaIdx].TextStart+uint32(dict.Lemmas[currForm.LemmaIdx].TextLen)]}
+	result := make([][]Word, 0)
+	w := 0
+	for _, s := range SplitSentences(tokens) {
+		start := w
+		for w < len(words) && words[w].TokenID < s.End {
+			w++
 		}
-	}
-
-	result := make([]Form, n)
-
-	lastBestForm := Form{}
-	lastMaxLogProb := -math.MaxFloat64
-	for form, step := range dp[n-1] {
-		if step.LogProb > lastMaxLogProb {
-			lastMaxLogProb = step.LogProb
-			lastBestForm = form
-		}
-	}
-
-	currForm := lastBestForm
-	for i := n - 1; i >= 0; i-- {
-		result[i] = currForm
-		currForm = dp[i][currForm].BackPtr
+		sentence := words[start:w:w]
+		l.resolve(sentence)
+		result = append(result, sentence)
 	}
 
 	return result
 }
 
-func (l *Lemmatizer) Disambiguate(tokens []Token) []Word {
+// Candidates returns the word tokens with their possible forms. The forms may
+// come from the user dictionary, so their lemmas are only valid through
+// FormLemma of the same Lemmatizer.
+func (l *Lemmatizer) Candidates(tokens []Token) []Word {
 	words := make([]Word, 0, len(tokens))
 
 	for i, token := range tokens {
@@ -222,7 +334,7 @@ func (l *Lemmatizer) Disambiguate(tokens []Token) []Word {
 						if pred.MatchLen < matchlen-1 {
 							break
 						}
-						forms = append(forms, Form{FEATS: pred.Tag & BigramMask, CountTotal: uint16(pred.RuleCounter)})
+						forms = append(forms, Form{FEATS: pred.Tag & BigramMask, CountTotal: uint16(min(pred.RuleCounter, math.MaxUint16))})
 					}
 				} else {
 					forms = append(forms, Form{FEATS: FEATS(0).SetPOS(NOUN)},
@@ -239,43 +351,50 @@ func (l *Lemmatizer) Disambiguate(tokens []Token) []Word {
 		}
 	}
 
+	return words
+}
+
+func (l *Lemmatizer) resolve(words []Word) {
 	forms := l.Viterbi(words)
 	for i := range words {
 		words[i].Options = []Form{forms[i]}
 		words[i].POS = forms[i].FEATS.POS()
 	}
-
-	return words
 }
 
+// LemmatizeTokens returns one lemma per token, stopwords included.
 func (l *Lemmatizer) LemmatizeTokens(tokens []Token) []string {
 	results := make([]string, 0, len(tokens))
-
-	for _, t := range tokens {
-		results = append(results, t.Text())
+	for _, t := range l.analyzeTokens(tokens, false) {
+		results = append(results, t.Lemma)
 	}
+	return results
+}
 
-	words := l.Disambiguate(tokens)
-	for _, w := range words {
-		if len(w.Options) > 0 {
-			form := w.Options[0]
-			if form.LemmaIdx == 0 {
-				predictions := l.base.SuffixPredictor.Predict(w.Text)
-				if len(predictions) > 0 {
-					results[w.TokenID] = predictions[0].Lemma
-				}
-				continue
+// FormLemma returns the lemma of a form of text. A user dictionary form whose
+// lemma this Lemmatizer does not hold yields text.
+func (l *Lemmatizer) FormLemma(form Form, text string) string {
+	if form.LemmaIdx&userLemmaFlag != 0 {
+		if l.user != nil {
+			if lemma, ok := l.user.lemmaText(form.LemmaIdx); ok {
+				return lemma
 			}
-			lemma := l.base.Dictionary.Lemmas[form.LemmaIdx]
-			lemma, _ = l.followLinks(lemma)
-			text := l.base.Dictionary.Texts[lemma.TextStart : lemma.TextStart+uint32(lemma.TextLen)]
-			results[w.TokenID] = text
 		}
-
+		return text
 	}
-	return results
+	if form.LemmaIdx == 0 {
+		predictions := l.base.SuffixPredictor.Predict(text)
+		if len(predictions) > 0 {
+			return predictions[0].Lemma
+		}
+		return text
+	}
+
+	lemma, _ := l.followLinks(l.base.Dictionary.Lemmas[form.LemmaIdx])
+	return l.base.Dictionary.exported(l.base.Dictionary.lemmaText(lemma))
 }
 
+// LemmatizeText returns one lemma per token of the text, stopwords included.
 func (l *Lemmatizer) LemmatizeText(text string) []string {
 	tokens := Tokenize(text, l.keywords)
 	return l.LemmatizeTokens(tokens)
@@ -302,9 +421,14 @@ func (l *Lemmatizer) lemmatizeByDict(word string) (string, POS, uint16, bool) {
 	maxScore := -2_000_000_000
 	maxForm := Form{}
 	resLemma := Lemma{}
+	bestLemmaScore := 0
 	for _, f := range forms {
+		if f.LemmaIdx&userLemmaFlag != 0 {
+			continue
+		}
 		lemma := l.base.Dictionary.Lemmas[f.LemmaIdx]
 		fromLemma, lemmaScore := l.followLinks(lemma)
+		bestLemmaScore = max(bestLemmaScore, lemmaScore)
 		score := 50*int(f.CountDocs) + int(f.CountTotal) + int(lemmaScore)
 		if score > maxScore {
 			maxScore = score
@@ -314,29 +438,103 @@ func (l *Lemmatizer) lemmatizeByDict(word string) (string, POS, uint16, bool) {
 
 	}
 
+	// User lemmas have no corpus counts, so user forms take the best lemma
+	// score of the dictionary readings and compete on their own counts.
+	for _, f := range forms {
+		if f.LemmaIdx&userLemmaFlag == 0 {
+			continue
+		}
+		if score := 50*int(f.CountDocs) + int(f.CountTotal) + bestLemmaScore; score > maxScore {
+			maxScore = score
+			maxForm = f
+		}
+	}
+
 	if maxScore > 0 {
-		return l.base.Dictionary.Texts[int(resLemma.TextStart) : int(resLemma.TextStart)+int(resLemma.TextLen)], maxForm.FEATS.POS(), 0, true
+		if maxForm.LemmaIdx&userLemmaFlag != 0 {
+			return l.FormLemma(maxForm, word), maxForm.FEATS.POS(), 0, true
+		}
+		return l.base.Dictionary.exported(l.base.Dictionary.lemmaText(resLemma)), maxForm.FEATS.POS(), 0, true
 	}
 
 	return word, POS(math.MaxUint8), 0, false
 }
 
 func (l *Lemmatizer) getForms(text string) []Form {
-	digest := xxhash.New()
-	digest.WriteString(text)
-	hash := digest.Sum64()
-
-	if idx, ok := l.base.Dictionary.FormTextIndex[hash]; ok {
-		formText := l.base.Dictionary.FormTexts[idx]
-		forms := make([]Form, 0, formText.FormLen)
-		for i := range formText.FormLen {
-			form := l.base.Dictionary.Forms[formText.FormIdx+uint32(i)]
-			forms = append(forms, form)
+	forms := l.dictForms(text)
+	if l.user != nil {
+		forms = l.user.overlay(text, forms)
+	}
+	return forms
+}
+
+func (l *Lemmatizer) dictForms(text string) []Form {
+	idx, ok := l.base.Dictionary.lookupFormText(text)
+	if !ok {
+		return nil
+	}
+
+	formText := l.base.Dictionary.FormTexts[idx]
+	forms := make([]Form, 0, formText.FormLen)
+	for i := range formText.FormLen {
+		form := l.base.Dictionary.Forms[formText.FormIdx+uint32(i)]
+		forms = append(forms, form)
+	}
+
+	return forms
+}
+
+func formTextHash(text string) uint64 {
+	return xxhash.Sum64String(text)
+}
+
+func (d *DictionaryBase) lookupFormText(text string) (uint32, bool) {
+	return d.lookupFormTextHash(text, formTextHash(text))
+}
+
+func (d *DictionaryBase) lookupFormTextHash(text string, hash uint64) (uint32, bool) {
+	if d.formIndex != nil {
+		i := sort.Search(len(d.formIndex), func(i int) bool { return d.formIndex[i].Hash >= hash })
+		for ; i < len(d.formIndex) && d.formIndex[i].Hash == hash; i++ {
+			if idx := d.formIndex[i].Idx; d.formText(d.FormTexts[idx]) == text {
+				return idx, true
+			}
+		}
+		return 0, false
+	}
+
+	idx, ok := d.FormTextIndex[hash]
+	if !ok {
+		return 0, false
+	}
+	if d.formText(d.FormTexts[idx]) == text {
+		return idx, true
+	}
+	for _, idx := range d.FormTextChains[hash] {
+		if d.formText(d.FormTexts[idx]) == text {
+			return idx, true
 		}
+	}
+	return 0, false
+}
 
-		return forms
+// BuildFormTextIndex rebuilds FormTextIndex and FormTextChains from FormTexts.
+func (d *DictionaryBase) BuildFormTextIndex() {
+	d.buildFormTextIndex(formTextHash)
+}
+
+func (d *DictionaryBase) buildFormTextIndex(hashText func(string) uint64) {
+	d.FormTextIndex = make(map[uint64]uint32, len(d.FormTexts))
+	d.FormTextChains = map[uint64][]uint32{}
+
+	for i, ft := range d.FormTexts {
+		hash := hashText(d.formText(ft))
+		if _, ok := d.FormTextIndex[hash]; ok {
+			d.FormTextChains[hash] = append(d.FormTextChains[hash], uint32(i))
+			continue
+		}
+		d.FormTextIndex[hash] = uint32(i)
 	}
-	return nil
 }
 
 // TODO
@@ -362,3 +560,23 @@ func (l *Lemmatizer) followLinks(lemma Lemma) (Lemma, int) {
 	}
 	return lemma, int(lemma.CountDocs) // + int(lemma.CountTotal)
 }
+
+func LoadDictionary(path string) (*DictionaryBase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var base DictionaryBase
+	decoder := gob.NewDecoder(file)
+	err = decoder.Decode(&base)
+	if err != nil {
+		return nil, err
+	}
+	if err := base.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &base, nil
+}
@@ -0,0 +1,217 @@
+package nlp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLemmatizeText(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	assert.Equal(t, []string{"мама", "мыть", "рама", "."}, l.LemmatizeText("Мама мыла раму."))
+	assert.Equal(t, []string{"в", "новый", "дом"}, l.LemmatizeText("в новом доме"))
+	assert.Equal(t, "читать", l.LemmatizeWord("Читающий"))
+	assert.Equal(t, "быстрый", l.LemmatizeWord("быстрее"))
+}
+
+func TestDisambiguateSentences(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	tokens := Tokenize("Мама мыла раму. Он читает книги.", l.keywords)
+	sentences := l.DisambiguateSentences(tokens)
+	require.Len(t, sentences, 2)
+	require.Len(t, sentences[0], 3)
+	require.Len(t, sentences[1], 3)
+	assert.Equal(t, VERB, sentences[0][1].POS)
+	assert.Equal(t, "книги", sentences[1][2].Text)
+	assert.Equal(t, 6, sentences[1][2].TokenID)
+}
+
+func TestAnalyze(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	parses := l.Analyze("Стали")
+	require.Len(t, parses, 5)
+	assert.Equal(t, "стать", parses[0].Lemma)
+	assert.Equal(t, VERB, parses[0].FEATS.POS())
+	assert.Equal(t, SourceDictionary, parses[0].Source)
+	assert.Equal(t, "сталь", parses[1].Lemma)
+	assert.Equal(t, Gen, parses[1].FEATS.Case())
+
+	total := 0.0
+	for _, p := range parses {
+		total += p.Probability
+	}
+	assert.InDelta(t, 1.0, total, 1e-9)
+
+	parses = l.Analyze("читающий")
+	require.Len(t, parses, 1)
+	assert.Equal(t, "читать", parses[0].Lemma)
+
+	assert.Empty(t, l.Analyze("абырвалг"))
+}
+
+func TestInflect(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	assert.Equal(t, []string{"москве"}, l.Inflect("Москва", FEATS(0).SetCase(Loc)))
+	assert.Equal(t, []string{"домов"}, l.Inflect("дом", FEATS(0).SetCase(Gen).SetNumber(Plur)))
+	assert.Equal(t, []string{"дома", "домов"}, l.Inflect("дом", FEATS(0).SetCase(Gen)))
+	assert.Equal(t, []string{"быстрее"}, l.Inflect("быстрый", FEATS(0).SetDegree(Cmp)))
+	assert.Empty(t, l.Inflect("москве", FEATS(0).SetCase(Nom)))
+
+	var texts []string
+	for _, f := range l.Lexeme("читать") {
+		texts = append(texts, f.Text)
+	}
+	assert.Equal(t, []string{"читать", "читает", "читающий"}, texts)
+}
+
+func TestAgreeWithNumber(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	assert.Equal(t, "файл", l.AgreeWithNumber("файл", 1))
+	assert.Equal(t, "файла", l.AgreeWithNumber("файл", 2))
+	assert.Equal(t, "файлов", l.AgreeWithNumber("файл", 5))
+	assert.Equal(t, "файлов", l.AgreeWithNumber("файл", 11))
+	assert.Equal(t, "файл", l.AgreeWithNumber("файл", 21))
+	assert.Equal(t, "файла", l.AgreeWithNumber("файл", -103))
+
+	assert.Equal(t, "новый файл", l.AgreeWithNumber("новый файл", 1))
+	assert.Equal(t, "новых файла", l.AgreeWithNumber("новый файл", 2))
+	assert.Equal(t, "новых файлов", l.AgreeWithNumber("новый файл", 12))
+	assert.Equal(t, "новая книга", l.AgreeWithNumber("новый книга", 31))
+	assert.Equal(t, "новые книги", l.AgreeWithNumber("новый книга", 3))
+	assert.Equal(t, "новых книг", l.AgreeWithNumber("новый книга", 100))
+}
+
+func TestAnalyzeText(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	text := "Мама мыла раму, читающий!"
+	tokens := l.AnalyzeText(text)
+	require.Len(t, tokens, 6)
+
+	assert.Equal(t, "Мама", tokens[0].Surface)
+	assert.Equal(t, "мама", tokens[0].Lemma)
+	assert.Equal(t, NOUN, tokens[0].POS)
+
+	assert.Equal(t, "мыть", tokens[1].Lemma)
+	assert.Equal(t, VERB, tokens[1].POS)
+	assert.Equal(t, Fem, tokens[1].FEATS.Gender())
+	assert.Greater(t, tokens[1].Confidence, 0.5)
+	assert.LessOrEqual(t, tokens[1].Confidence, 1.0)
+
+	assert.Equal(t, ",", tokens[3].Lemma)
+	assert.Equal(t, PUNCT, tokens[3].POS)
+	assert.Equal(t, TokenPunct, tokens[3].Type)
+
+	assert.Equal(t, "читать", tokens[4].Lemma)
+	assert.Equal(t, "читающий", text[tokens[4].Start:tokens[4].End])
+}
+
+func TestNewLemmatizerMissingLinkType(t *testing.T) {
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+	delete(data.Dictionary.LinkTypes, "INFN-VERB")
+
+	_, err := NewLemmatizer(data)
+	assert.ErrorContains(t, err, "INFN-VERB")
+
+	_, err = NewLemmatizer(data, WithImportantLinks("INFN-PRTF", "UNKNOWN"))
+	assert.ErrorContains(t, err, "UNKNOWN")
+
+	l, err := NewLemmatizer(data, WithImportantLinks("ADJF-COMP"))
+	require.NoError(t, err)
+	assert.Equal(t, "читающий", l.LemmatizeWord("читающий"))
+	assert.Equal(t, "быстрый", l.LemmatizeWord("быстрее"))
+}
+
+func TestCandidatesClampRuleCounter(t *testing.T) {
+	l := newSuffixLemmatizer(t)
+	predictor := &l.base.SuffixPredictor
+	for i := range predictor.RulePool {
+		predictor.RulePool[i].Counter += 1 << 16
+	}
+	for i := range predictor.NodePool {
+		predictor.NodePool[i].Counter += 1 << 16
+	}
+
+	words := l.Candidates(Tokenize("сталями", l.keywords))
+	require.Len(t, words, 1)
+	require.NotEmpty(t, words[0].Options)
+	for _, form := range words[0].Options {
+		assert.Equal(t, uint16(math.MaxUint16), form.CountTotal)
+	}
+}
+
+func TestLemmatizerOptions(t *testing.T) {
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+
+	_, err := NewLemmatizer(data, WithSmoothing(0))
+	assert.Error(t, err)
+	_, err = NewLemmatizer(data, WithKeywords(nil))
+	assert.Error(t, err)
+	_, err = NewLemmatizer(data, WithSuffixPredictor(SuffixPredictorBase{NodePool: []SuffixNode{{ChildrenLen: 1}}}))
+	assert.Error(t, err)
+
+	l := newTestLemmatizer(t)
+	smoothed, err := NewLemmatizer(data, WithSmoothing(2))
+	require.NoError(t, err)
+	assert.NotEqual(t, l.GetLogScore(startTag, noun(Fem, Nom, Sing), Word{}), smoothed.GetLogScore(startTag, noun(Fem, Nom, Sing), Word{}))
+
+	keywords, err := NewLemmatizer(data, WithKeywords(NewKeywords(KeywordSet{"мыла раму"})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"мама", "мыла раму"}, keywords.LemmatizeText("мама мыла раму"))
+
+	stopwords, err := NewLemmatizer(data, WithStopwords(NewStopwords(StopwordSet{"в"}).AddPOS(PUNCT)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"новый", "дом"}, tokenLemmas(stopwords.AnalyzeText("В новом доме.")))
+	assert.Equal(t, []string{"в", "новый", "дом", "."}, stopwords.LemmatizeText("В новом доме."))
+	sentences := stopwords.AnalyzeSentences(Tokenize("В доме. В Москве.", stopwords.Keywords()))
+	require.Len(t, sentences, 2)
+	assert.Equal(t, "москва", sentences[1][0].Lemma)
+
+	predicted, err := NewLemmatizer(data, WithSuffixPredictor(suffixFixtureData().SuffixPredictor))
+	require.NoError(t, err)
+	parses := predicted.Analyze("сталями")
+	require.NotEmpty(t, parses)
+	assert.Equal(t, SourceSuffixPredictor, parses[0].Source)
+	assert.Empty(t, l.Analyze("сталями"))
+}
+
+func TestProfiles(t *testing.T) {
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+	lemmas := func(p Profile) []string {
+		l, err := NewLemmatizer(data, WithProfile(p))
+		require.NoError(t, err)
+		return []string{l.LemmatizeWord("читающий"), l.LemmatizeWord("быстрее")}
+	}
+
+	assert.Equal(t, []string{"читать", "быстрый"}, lemmas(ProfileSearch))
+	assert.Equal(t, []string{"читающий", "быстрый"}, lemmas(ProfileUD))
+	assert.Equal(t, []string{"читающий", "быстрее"}, lemmas(ProfileDictionary))
+
+	assert.Equal(t, DefaultImportantLinks, ProfileSearch.Links)
+	assert.NotSame(t, &DefaultImportantLinks[0], &ProfileSearch.Links[0])
+
+	p, err := ProfileByName("ud")
+	require.NoError(t, err)
+	assert.Equal(t, ProfileUD, p)
+	_, err = ProfileByName("nope")
+	assert.Error(t, err)
+}
+
+func TestLinkChain(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	parses := l.Analyze("читающий")
+	require.Len(t, parses, 1)
+	assert.Equal(t, "читать", parses[0].Lemma)
+	assert.Equal(t, []LinkStep{{Type: "INFN-PRTF", From: "читать", To: "читающий"}}, parses[0].Chain)
+
+	assert.Nil(t, l.Analyze("мама")[0].Chain)
+	assert.Nil(t, l.LinkChain(Form{}))
+}
@@ -0,0 +1,20 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package nlp
+
+import (
+	"io"
+	"os"
+)
+
+func mapFile(file *os.File, size int) ([]byte, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(file, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func unmapFile(b []byte) error {
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package nlp
+
+import (
+	"os"
+	"syscall"
+)
+
+func mapFile(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func unmapFile(b []byte) error {
+	return syscall.Munmap(b)
+}
@@ -0,0 +1,261 @@
+package nlp
+
+import (
+	"math"
+	"slices"
+)
+
+type TagPath struct {
+	Forms   []Form
+	LogProb float64
+}
+
+type nbestEntry struct {
+	logProb float64
+	prev    int32
+	rank    int32
+}
+
+// NBest returns up to n best tag sequences under the model Viterbi uses, best
+// first. Forms sharing a tag are scored identically, so each path holds one
+// representative form per tag, the same one Viterbi would pick.
+func (l *Lemmatizer) NBest(sentence []Word, n int) []TagPath {
+	if len(sentence) == 0 || n <= 0 {
+		return nil
+	}
+
+	// Words without options get a zero Form and split the sentence into runs,
+	// as in Viterbi; the paths of the runs combine independently.
+	paths := []TagPath{{Forms: make([]Form, len(sentence))}}
+	for _, r := range optionRuns(sentence) {
+		var run []TagPath
+		if l.trigram {
+			run = l.nbestTrigram(sentence[r.start:r.end], n)
+		} else {
+			run = l.nbestBigram(sentence[r.start:r.end], n)
+		}
+		paths = combinePaths(paths, run, r.start, n)
+	}
+	return paths
+}
+
+type optionRun struct {
+	start, end int
+}
+
+// optionRuns returns the maximal runs of words that have options.
+func optionRuns(sentence []Word) []optionRun {
+	var runs []optionRun
+	start := 0
+	for i := 0; i <= len(sentence); i++ {
+		if i < len(sentence) && len(sentence[i].Options) > 0 {
+			continue
+		}
+		if start < i {
+			runs = append(runs, optionRun{start: start, end: i})
+		}
+		start = i + 1
+	}
+	return runs
+}
+
+// combinePaths extends each of paths with each path of the run starting at
+// word start and keeps the n best.
+func combinePaths(paths, run []TagPath, start, n int) []TagPath {
+	type pair struct {
+		path, run int
+		logProb   float64
+	}
+	pairs := make([]pair, 0, len(paths)*len(run))
+	for pi, p := range paths {
+		for ri, r := range run {
+			pairs = append(pairs, pair{path: pi, run: ri, logProb: p.LogProb + r.LogProb})
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int {
+		switch {
+		case a.logProb > b.logProb:
+			return -1
+		case a.logProb < b.logProb:
+			return 1
+		}
+		return 0
+	})
+
+	result := make([]TagPath, min(n, len(pairs)))
+	for i := range result {
+		forms := slices.Clone(paths[pairs[i].path].Forms)
+		copy(forms[start:], run[pairs[i].run].Forms)
+		result[i] = TagPath{Forms: forms, LogProb: pairs[i].logProb}
+	}
+	return result
+}
+
+func (l *Lemmatizer) nbestBigram(sentence []Word, n int) []TagPath {
+	s := viterbiScratchPool.Get().(*viterbiScratch)
+	defer viterbiScratchPool.Put(s)
+	s.prepare(l.scores, sentence)
+
+	entries := make([][]nbestEntry, len(s.tags))
+	startIdx := l.scores.tagIndex(startTag)
+	for k := s.offsets[0]; k < s.offsets[1]; k++ {
+		score := l.scores.transition(startIdx, s.tagIdx[k])*transitionCoeff(startTag, s.tags[k]) + s.emission[k]
+		entries[k] = []nbestEntry{{logProb: score, prev: -1}}
+	}
+
+	var buf []nbestEntry
+	for i := 1; i < len(sentence); i++ {
+		for k := s.offsets[i]; k < s.offsets[i+1]; k++ {
+			buf = buf[:0]
+			for p := s.offsets[i-1]; p < s.offsets[i]; p++ {
+				step := l.scores.transition(s.tagIdx[p], s.tagIdx[k])*transitionCoeff(s.tags[p], s.tags[k]) + s.emission[k]
+				for r, e := range entries[p] {
+					buf = append(buf, nbestEntry{logProb: e.logProb + step, prev: int32(p), rank: int32(r)})
+				}
+			}
+			entries[k] = topEntries(buf, n)
+		}
+	}
+
+	last := len(sentence) - 1
+	buf = buf[:0]
+	for k := s.offsets[last]; k < s.offsets[last+1]; k++ {
+		for r, e := range entries[k] {
+			buf = append(buf, nbestEntry{logProb: e.logProb, prev: int32(k), rank: int32(r)})
+		}
+	}
+
+	final := topEntries(buf, n)
+	paths := make([]TagPath, len(final))
+	for pi, f := range final {
+		forms := make([]Form, len(sentence))
+		k, r := int(f.prev), int(f.rank)
+		for i := last; i >= 0; i-- {
+			forms[i] = sentence[i].Options[s.formIdx[k]]
+			e := entries[k][r]
+			k, r = int(e.prev), int(e.rank)
+		}
+		paths[pi] = TagPath{Forms: forms, LogProb: f.logProb}
+	}
+
+	return paths
+}
+
+func topEntries(candidates []nbestEntry, n int) []nbestEntry {
+	slices.SortStableFunc(candidates, func(a, b nbestEntry) int {
+		switch {
+		case a.logProb > b.logProb:
+			return -1
+		case a.logProb < b.logProb:
+			return 1
+		}
+		return 0
+	})
+	return slices.Clone(candidates[:min(n, len(candidates))])
+}
+
+// Marginals runs forward-backward under the model Viterbi uses and returns the
+// posterior probability of every option of every word, aligned with
+// Word.Options. The posterior of a tag is shared between the forms carrying
+// it in proportion to their corpus counts.
+func (l *Lemmatizer) Marginals(sentence []Word) [][]float64 {
+	if len(sentence) == 0 {
+		return nil
+	}
+
+	// Runs split by words without options are independent, as in Viterbi.
+	result := make([][]float64, len(sentence))
+	for _, r := range optionRuns(sentence) {
+		if l.trigram {
+			copy(result[r.start:], l.marginalsTrigram(sentence[r.start:r.end]))
+		} else {
+			copy(result[r.start:], l.marginalsBigram(sentence[r.start:r.end]))
+		}
+	}
+	for i := range result {
+		if result[i] == nil {
+			result[i] = []float64{}
+		}
+	}
+	return result
+}
+
+func (l *Lemmatizer) marginalsBigram(sentence []Word) [][]float64 {
+	s := viterbiScratchPool.Get().(*viterbiScratch)
+	defer viterbiScratchPool.Put(s)
+	s.prepare(l.scores, sentence)
+
+	n := len(sentence)
+	forward := make([]float64, len(s.tags))
+	backward := make([]float64, len(s.tags))
+	terms := make([]float64, 0, 16)
+
+	startIdx := l.scores.tagIndex(startTag)
+	for k := s.offsets[0]; k < s.offsets[1]; k++ {
+		forward[k] = l.scores.transition(startIdx, s.tagIdx[k])*transitionCoeff(startTag, s.tags[k]) + s.emission[k]
+	}
+	for i := 1; i < n; i++ {
+		for k := s.offsets[i]; k < s.offsets[i+1]; k++ {
+			terms = terms[:0]
+			for p := s.offsets[i-1]; p < s.offsets[i]; p++ {
+				terms = append(terms, forward[p]+l.scores.transition(s.tagIdx[p], s.tagIdx[k])*transitionCoeff(s.tags[p], s.tags[k]))
+			}
+			forward[k] = logSumExp(terms) + s.emission[k]
+		}
+	}
+
+	for i := n - 2; i >= 0; i-- {
+		for p := s.offsets[i]; p < s.offsets[i+1]; p++ {
+			terms = terms[:0]
+			for k := s.offsets[i+1]; k < s.offsets[i+2]; k++ {
+				terms = append(terms, l.scores.transition(s.tagIdx[p], s.tagIdx[k])*transitionCoeff(s.tags[p], s.tags[k])+s.emission[k]+backward[k])
+			}
+			backward[p] = logSumExp(terms)
+		}
+	}
+
+	logZ := logSumExp(forward[s.offsets[n-1]:s.offsets[n]])
+
+	result := make([][]float64, n)
+	for i, w := range sentence {
+		result[i] = make([]float64, len(w.Options))
+		for k := s.offsets[i]; k < s.offsets[i+1]; k++ {
+			shareTagPosterior(result[i], w.Options, s.tags[k], math.Exp(forward[k]+backward[k]-logZ))
+		}
+	}
+
+	return result
+}
+
+// shareTagPosterior adds the posterior of a tag to the options carrying it in
+// proportion to their corpus counts.
+func shareTagPosterior(result []float64, options []Form, tag FEATS, posterior float64) {
+	tag &= BigramMask
+	weight := 0.0
+	for _, f := range options {
+		if f.FEATS&BigramMask == tag {
+			weight += float64(f.CountTotal) + 1
+		}
+	}
+	for fi, f := range options {
+		if f.FEATS&BigramMask == tag {
+			result[fi] += posterior * (float64(f.CountTotal) + 1) / weight
+		}
+	}
+}
+
+func logSumExp(values []float64) float64 {
+	best := math.Inf(-1)
+	for _, v := range values {
+		best = max(best, v)
+	}
+	if math.IsInf(best, -1) {
+		return best
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += math.Exp(v - best)
+	}
+	return best + math.Log(sum)
+}
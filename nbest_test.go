@@ -0,0 +1,159 @@
+package nlp
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enumeratePaths scores every sequence of unique per-word tags with GetLogScore,
+// or with the trigram model when it is on.
+func enumeratePaths(l *Lemmatizer, sentence []Word) []TagPath {
+	options := make([][]Form, len(sentence))
+	for i, w := range sentence {
+		seen := map[FEATS]bool{}
+		for _, f := range w.Options {
+			if !seen[f.FEATS&BigramMask] {
+				seen[f.FEATS&BigramMask] = true
+				options[i] = append(options[i], f)
+			}
+		}
+	}
+
+	var paths []TagPath
+	var walk func(i int, prev FEATS, score float64, forms []Form)
+	walk = func(i int, prev FEATS, score float64, forms []Form) {
+		if i == len(sentence) {
+			paths = append(paths, TagPath{Forms: append([]Form(nil), forms...), LogProb: score})
+			return
+		}
+		for _, f := range options[i] {
+			walk(i+1, f.FEATS, score+l.GetLogScore(prev, f.FEATS, sentence[i]), append(forms, f))
+		}
+	}
+	walk(0, startTag, 0, nil)
+
+	if l.trigram {
+		for i := range paths {
+			paths[i].LogProb = trigramPathScore(l, sentence, paths[i].Forms)
+		}
+	}
+	return paths
+}
+
+func TestNBest(t *testing.T) {
+	t.Run("bigram", func(t *testing.T) { testNBest(t, newTestLemmatizer(t)) })
+	t.Run("trigram", func(t *testing.T) { testNBest(t, newTrigramLemmatizer(t)) })
+}
+
+func testNBest(t *testing.T, l *Lemmatizer) {
+	rnd := rand.New(rand.NewSource(4))
+
+	for range 200 {
+		sentence := randomSentence(l, rnd, 1+rnd.Intn(5))
+		all := enumeratePaths(l, sentence)
+		sort.SliceStable(all, func(i, j int) bool { return all[i].LogProb > all[j].LogProb })
+
+		paths := l.NBest(sentence, 5)
+		require.Len(t, paths, min(5, len(all)))
+		assert.Equal(t, l.Viterbi(sentence), paths[0].Forms)
+		if l.trigram {
+			assert.InDelta(t, trigramPathScore(l, sentence, l.Viterbi(sentence)), paths[0].LogProb, 1e-9)
+		}
+		for i, p := range paths {
+			assert.InDelta(t, all[i].LogProb, p.LogProb, 1e-9)
+			if i > 0 {
+				assert.LessOrEqual(t, p.LogProb, paths[i-1].LogProb)
+			}
+		}
+	}
+
+	assert.Nil(t, l.NBest(nil, 3))
+	assert.Nil(t, l.NBest(randomSentence(l, rnd, 3), 0))
+}
+
+func TestMarginals(t *testing.T) {
+	t.Run("bigram", func(t *testing.T) { testMarginals(t, newTestLemmatizer(t)) })
+	t.Run("trigram", func(t *testing.T) { testMarginals(t, newTrigramLemmatizer(t)) })
+}
+
+func testMarginals(t *testing.T, l *Lemmatizer) {
+	rnd := rand.New(rand.NewSource(5))
+
+	for range 200 {
+		sentence := randomSentence(l, rnd, 1+rnd.Intn(5))
+		marginals := l.Marginals(sentence)
+		require.Len(t, marginals, len(sentence))
+
+		all := enumeratePaths(l, sentence)
+		logZ := math.Inf(-1)
+		for _, p := range all {
+			logZ = logSumExp([]float64{logZ, p.LogProb})
+		}
+
+		for i, w := range sentence {
+			require.Len(t, marginals[i], len(w.Options))
+
+			sum := 0.0
+			for _, m := range marginals[i] {
+				sum += m
+			}
+			assert.InDelta(t, 1, sum, 1e-9)
+
+			for _, f := range w.Options {
+				tag := f.FEATS & BigramMask
+				expected := 0.0
+				for _, p := range all {
+					if p.Forms[i].FEATS&BigramMask == tag {
+						expected += math.Exp(p.LogProb - logZ)
+					}
+				}
+				assert.InDelta(t, expected, tagMarginal(w.Options, marginals[i], f.FEATS), 1e-9)
+			}
+		}
+	}
+}
+
+func TestMarginalsAmbiguity(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	words := l.Candidates(Tokenize("мама мыла раму", l.keywords))
+	marginals := l.Marginals(words)
+
+	require.Len(t, words[1].Options, 2)
+	assert.Greater(t, marginals[1][0], marginals[1][1])
+	assert.Greater(t, marginals[1][1], 0.0)
+	assert.InDelta(t, 1, marginals[0][0], 1e-9)
+}
+
+func TestNBestGaps(t *testing.T) {
+	t.Run("bigram", func(t *testing.T) { testNBestGaps(t, newTestLemmatizer(t)) })
+	t.Run("trigram", func(t *testing.T) { testNBestGaps(t, newTrigramLemmatizer(t)) })
+}
+
+func testNBestGaps(t *testing.T, l *Lemmatizer) {
+	words := l.Candidates(Tokenize("мама мыла раму", l.keywords))
+	gapped := []Word{words[0], {}, words[1], words[2]}
+
+	head, tail := l.NBest(words[:1], 3), l.NBest(words[1:], 3)
+	paths := l.NBest(gapped, 3)
+	require.Len(t, paths, min(3, len(head)*len(tail)))
+	assert.Equal(t, l.Viterbi(gapped), paths[0].Forms)
+	assert.InDelta(t, head[0].LogProb+tail[0].LogProb, paths[0].LogProb, 1e-9)
+	for _, p := range paths {
+		assert.Equal(t, Form{}, p.Forms[1])
+	}
+
+	marginals := l.Marginals(gapped)
+	require.Len(t, marginals, len(gapped))
+	assert.Equal(t, l.Marginals(words[:1]), marginals[:1])
+	assert.Empty(t, marginals[1])
+	assert.Equal(t, l.Marginals(words[1:]), marginals[2:])
+
+	assert.Equal(t, []TagPath{{Forms: []Form{{}, {}}}}, l.NBest([]Word{{}, {}}, 3))
+	assert.Equal(t, [][]float64{{}, {}}, l.Marginals([]Word{{}, {}}))
+}
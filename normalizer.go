@@ -3,6 +3,7 @@ package nlp
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/runes"
 	"golang.org/x/text/transform"
@@ -10,7 +11,11 @@ import (
 )
 
 func Normalize(word string) string {
-	word = strings.ToLower(strings.TrimSpace(word))
+	return normalize(strings.TrimSpace(word))
+}
+
+func normalize(word string) string {
+	word = strings.ToLower(word)
 
 	word = strings.ReplaceAll(word, "ё", "е")
 
@@ -40,3 +45,66 @@ func needsTransformation(s string) bool {
 
 	return false
 }
+
+func normalizeWithOffsets(text string) (string, []int) {
+	start := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	end := len(strings.TrimRightFunc(text, unicode.IsSpace))
+	if start >= end {
+		return "", []int{start}
+	}
+
+	var sb strings.Builder
+	sb.Grow(end - start)
+	offsets := make([]int, 0, end-start+1)
+
+	groupStart := start
+	for groupStart < end {
+		if c := text[groupStart]; c < utf8.RuneSelf && (groupStart+1 == end || text[groupStart+1] < utf8.RuneSelf) {
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			sb.WriteByte(c)
+			offsets = append(offsets, groupStart)
+			groupStart++
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[groupStart:end])
+		groupEnd := groupStart + size
+		for groupEnd < end {
+			r, size := utf8.DecodeRuneInString(text[groupEnd:end])
+			if !unicode.IsMark(r) {
+				break
+			}
+			groupEnd += size
+		}
+
+		if r, _ := utf8.DecodeRuneInString(text[groupStart:]); groupEnd == groupStart+size && isPlainCyrillic(r) {
+			r = unicode.ToLower(r)
+			if r == 'ё' {
+				r = 'е'
+			}
+			n := sb.Len()
+			sb.WriteRune(r)
+			for range sb.Len() - n {
+				offsets = append(offsets, groupStart)
+			}
+			groupStart = groupEnd
+			continue
+		}
+
+		group := normalize(text[groupStart:groupEnd])
+		sb.WriteString(group)
+		for range len(group) {
+			offsets = append(offsets, groupStart)
+		}
+		groupStart = groupEnd
+	}
+	offsets = append(offsets, end)
+
+	return sb.String(), offsets
+}
+
+func isPlainCyrillic(r rune) bool {
+	return (r >= 0x400 && r <= 0x482) || (r >= 0x48a && r <= 0x52f)
+}
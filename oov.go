@@ -2,14 +2,17 @@ package nlp
 
 import (
 	"encoding/gob"
+	"fmt"
 	"os"
 	"sort"
+	"strings"
 )
 
 type PredictionRule struct {
 	Tag         FEATS
 	Counter     uint32
 	AppendStart uint32
+	Score       float32
 	AppendLen   uint8
 	Cut         uint8
 }
@@ -19,6 +22,8 @@ type SuffixPredictorBase struct {
 	NodePool    []SuffixNode
 	EdgesPool   []Edge
 	AppendTexts string
+
+	mapped bool
 }
 
 type SuffixNode struct {
@@ -55,6 +60,10 @@ type Prediction struct {
 }
 
 func (p *SuffixPredictorBase) Predict(word string) []Prediction {
+	if len(p.NodePool) == 0 {
+		return nil
+	}
+
 	runes := []rune(word)
 	node := &p.NodePool[0]
 	var results []Prediction
@@ -67,14 +76,26 @@ func (p *SuffixPredictorBase) Predict(word string) []Prediction {
 
 		for ri := range node.RulesLen {
 			rule := p.RulePool[node.RulesIdx+uint32(ri)]
+			if int(rule.Cut) > len(runes) {
+				continue
+			}
 
 			base := string(runes[:len(runes)-int(rule.Cut)])
 			lemma := base + p.AppendTexts[rule.AppendStart:rule.AppendStart+uint32(rule.AppendLen)]
+			if base == "" && p.mapped {
+				// The concatenation returns the mapped append text itself.
+				lemma = strings.Clone(lemma)
+			}
+
+			score := float64(rule.Score)
+			if score == 0 {
+				score = float64(rule.Counter) / float64(node.Counter)
+			}
 
 			results = append(results, Prediction{
 				Lemma:       lemma,
 				Tag:         rule.Tag,
-				Score:       float64(rule.Counter) / float64(node.Counter), // TODO: поменять на посчитанную при обучении
+				Score:       score,
 				RuleCounter: rule.Counter,
 				NodeCounter: node.Counter,
 				MatchLen:    len(runes) - i,
@@ -96,6 +117,7 @@ func LoadOOV(path string) (*SuffixPredictorBase, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	var base SuffixPredictorBase
 	decoder := gob.NewDecoder(file)
@@ -103,6 +125,9 @@ func LoadOOV(path string) (*SuffixPredictorBase, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := base.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
 
 	return &base, nil
 }
@@ -0,0 +1,204 @@
+package nlp
+
+import "sort"
+
+type SuffixPredictorBuilder struct {
+	MaxSuffixLen    int
+	MinRuleCount    uint32
+	MinNodeCount    uint32
+	MaxRulesPerNode int
+
+	root *suffixTrieNode
+}
+
+type suffixRuleKey struct {
+	Tag    FEATS
+	Cut    uint8
+	Append string
+}
+
+type suffixTrieNode struct {
+	children map[rune]*suffixTrieNode
+	rules    map[suffixRuleKey]uint32
+	counter  uint32
+}
+
+func newSuffixTrieNode() *suffixTrieNode {
+	return &suffixTrieNode{
+		children: map[rune]*suffixTrieNode{},
+		rules:    map[suffixRuleKey]uint32{},
+	}
+}
+
+func NewSuffixPredictorBuilder() *SuffixPredictorBuilder {
+	return &SuffixPredictorBuilder{
+		MaxSuffixLen:    7,
+		MinRuleCount:    1,
+		MinNodeCount:    1,
+		MaxRulesPerNode: 32,
+		root:            newSuffixTrieNode(),
+	}
+}
+
+func (b *SuffixPredictorBuilder) Add(form, lemma string, feats FEATS, count uint32) {
+	if count == 0 {
+		return
+	}
+
+	formRunes := []rune(Normalize(form))
+	lemmaRunes := []rune(Normalize(lemma))
+
+	prefix := 0
+	for prefix < len(formRunes) && prefix < len(lemmaRunes) && formRunes[prefix] == lemmaRunes[prefix] {
+		prefix++
+	}
+
+	cut := len(formRunes) - prefix
+	appendText := string(lemmaRunes[prefix:])
+	if cut > 255 || len(appendText) > 255 {
+		return
+	}
+	key := suffixRuleKey{Tag: feats, Cut: uint8(cut), Append: appendText}
+
+	// A rule cutting more than the trie is deep goes to the deepest node, which
+	// matches the form as closely as the trie can.
+	deepest := min(len(formRunes), b.MaxSuffixLen)
+
+	node := b.root
+	node.counter += count
+	for depth := 1; depth <= deepest; depth++ {
+		c := formRunes[len(formRunes)-depth]
+		child, ok := node.children[c]
+		if !ok {
+			child = newSuffixTrieNode()
+			node.children[c] = child
+		}
+		node = child
+		node.counter += count
+
+		if depth >= cut || depth == deepest {
+			node.rules[key] += count
+		}
+	}
+}
+
+func (b *SuffixPredictorBuilder) AddDictionary(d *DictionaryBase) {
+	for _, ft := range d.FormTexts {
+		text := d.formText(ft)
+		for i := range ft.FormLen {
+			form := d.Forms[ft.FormIdx+uint32(i)]
+			if form.LemmaIdx == 0 {
+				continue
+			}
+			b.Add(text, d.lemmaText(d.Lemmas[form.LemmaIdx]), form.FEATS, uint32(form.CountTotal)+1)
+		}
+	}
+}
+
+func (b *SuffixPredictorBuilder) Build() SuffixPredictorBase {
+	var p SuffixPredictorBase
+	appendIdx := map[string]uint32{}
+
+	type queued struct {
+		node   *suffixTrieNode
+		idx    int
+		scores map[suffixRuleKey]float64
+	}
+
+	p.NodePool = append(p.NodePool, SuffixNode{Counter: b.root.counter})
+	queue := []queued{{node: b.root, idx: 0}}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		children := make([]rune, 0, len(item.node.children))
+		for c, child := range item.node.children {
+			if child.counter >= b.MinNodeCount {
+				children = append(children, c)
+			}
+		}
+		sort.Slice(children, func(i, j int) bool {
+			ci, cj := item.node.children[children[i]], item.node.children[children[j]]
+			if ci.counter != cj.counter {
+				return ci.counter > cj.counter
+			}
+			return children[i] < children[j]
+		})
+		if len(children) > 255 {
+			children = children[:255]
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i] < children[j] })
+
+		p.NodePool[item.idx].ChildrenIdx = uint32(len(p.EdgesPool))
+		p.NodePool[item.idx].ChildrenLen = uint8(len(children))
+		for _, c := range children {
+			child := item.node.children[c]
+			childIdx := len(p.NodePool)
+			p.EdgesPool = append(p.EdgesPool, Edge{Char: c, NodeIdx: childIdx})
+			p.NodePool = append(p.NodePool, SuffixNode{Counter: child.counter})
+			queue = append(queue, queued{node: child, idx: childIdx, scores: b.scoreRules(child, item.scores)})
+		}
+
+		if item.idx == 0 {
+			continue
+		}
+
+		rules := make([]suffixRuleKey, 0, len(item.node.rules))
+		for key, count := range item.node.rules {
+			if count >= b.MinRuleCount {
+				rules = append(rules, key)
+			}
+		}
+		sort.Slice(rules, func(i, j int) bool {
+			si, sj := item.scores[rules[i]], item.scores[rules[j]]
+			if si != sj {
+				return si > sj
+			}
+			if rules[i].Tag != rules[j].Tag {
+				return rules[i].Tag < rules[j].Tag
+			}
+			if rules[i].Cut != rules[j].Cut {
+				return rules[i].Cut < rules[j].Cut
+			}
+			return rules[i].Append < rules[j].Append
+		})
+		if len(rules) > min(b.MaxRulesPerNode, 255) {
+			rules = rules[:min(b.MaxRulesPerNode, 255)]
+		}
+
+		p.NodePool[item.idx].RulesIdx = uint32(len(p.RulePool))
+		p.NodePool[item.idx].RulesLen = uint8(len(rules))
+		for _, key := range rules {
+			start, ok := appendIdx[key.Append]
+			if !ok {
+				start = uint32(len(p.AppendTexts))
+				appendIdx[key.Append] = start
+				p.AppendTexts += key.Append
+			}
+			p.RulePool = append(p.RulePool, PredictionRule{
+				Tag:         key.Tag,
+				Counter:     item.node.rules[key],
+				AppendStart: start,
+				AppendLen:   uint8(len(key.Append)),
+				Cut:         key.Cut,
+				Score:       float32(item.scores[key]),
+			})
+		}
+	}
+
+	return p
+}
+
+// Rule scores are interpolated with the parent (shorter) suffix using Witten-Bell
+// weights, so rare long suffixes back off to the statistics of their shorter ones.
+func (b *SuffixPredictorBuilder) scoreRules(node *suffixTrieNode, parent map[suffixRuleKey]float64) map[suffixRuleKey]float64 {
+	scores := make(map[suffixRuleKey]float64, len(node.rules))
+	n := float64(node.counter)
+	lambda := n / (n + float64(len(node.rules)))
+
+	for key, count := range node.rules {
+		scores[key] = lambda*float64(count)/n + (1-lambda)*parent[key]
+	}
+	return scores
+}
@@ -0,0 +1,56 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuffixPredictorBuilder(t *testing.T) {
+	data := buildFixtureData(fixtureForms, fixtureLinks, nil)
+
+	builder := NewSuffixPredictorBuilder()
+	builder.AddDictionary(&data.Dictionary)
+	builder.Add("Айфоны", "айфон", noun(Masc, Nom, Plur), 3)
+	predictor := builder.Build()
+
+	predictions := predictor.Predict("гаму")
+	require.NotEmpty(t, predictions)
+	assert.Equal(t, "гама", predictions[0].Lemma)
+	assert.Equal(t, noun(Fem, Acc, Sing), predictions[0].Tag)
+	assert.Equal(t, 3, predictions[0].MatchLen)
+
+	predictions = predictor.Predict("смартфоны")
+	require.NotEmpty(t, predictions)
+	assert.Equal(t, "смартфон", predictions[0].Lemma)
+
+	for _, word := range []string{"у", "ы", "новом", "хламов"} {
+		for _, p := range predictor.Predict(word) {
+			assert.Greater(t, p.Score, 0.0)
+			assert.LessOrEqual(t, p.Score, 1.0)
+		}
+	}
+
+	// The cut of a suppletive pair can exceed MaxSuffixLen.
+	long := NewSuffixPredictorBuilder()
+	long.Add("наилучшего", "хороший", adj(Masc, Gen, Sing), 2)
+	longPredictor := long.Build()
+	predictions = longPredictor.Predict("наилучшего")
+	require.NotEmpty(t, predictions)
+	assert.Equal(t, "хороший", predictions[0].Lemma)
+	assert.Equal(t, long.MaxSuffixLen, predictions[0].MatchLen)
+
+	pruning := NewSuffixPredictorBuilder()
+	pruning.MinRuleCount = 5
+	pruning.MaxRulesPerNode = 1
+	pruning.AddDictionary(&data.Dictionary)
+	pruned := pruning.Build()
+	assert.Less(t, len(pruned.RulePool), len(predictor.RulePool))
+	for _, node := range pruned.NodePool {
+		assert.LessOrEqual(t, node.RulesLen, uint8(1))
+	}
+	for _, rule := range pruned.RulePool {
+		assert.GreaterOrEqual(t, rule.Counter, uint32(5))
+	}
+}
@@ -0,0 +1,65 @@
+package nlp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Save writes the data in the binary format read by Load and OpenBinary.
+func (d *LemmatizerData) Save(w io.Writer) error {
+	return WriteBinary(w, d)
+}
+
+// SaveFile writes the data to a temporary file next to path and renames it
+// into place, so a reader never sees a partially written file.
+func (d *LemmatizerData) SaveFile(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := file.Chmod(0o644); err != nil {
+		file.Close()
+		return err
+	}
+	if err := d.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Load reads data written by Save, verifying its header, checksums and the
+// bounds of every index it holds.
+func Load(r io.Reader) (*LemmatizerData, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBinary(b)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func LoadFile(path string) (*LemmatizerData, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package nlp
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Profile selects the link types followed from the dictionary lemma of a form
+// to the lemma the Lemmatizer reports.
+type Profile struct {
+	Name  string
+	Links []string
+}
+
+var (
+	// ProfileSearch collapses every related lemma, including participles,
+	// gerunds and feminine or plural nouns, so that they match in search.
+	ProfileSearch = Profile{Name: "search", Links: slices.Clone(DefaultImportantLinks)}
+
+	// ProfileUD follows Universal Dependencies conventions: short and
+	// comparative forms go to the full positive adjective and finite verbs and
+	// gerunds to the infinitive, while participles and feminine nouns keep
+	// their own lemma.
+	ProfileUD = Profile{Name: "ud", Links: []string{"ADJF-ADJS", "ADJF-COMP", "INFN-VERB", "INFN-GRND", "PRTF-PRTS",
+		"ADJF-SUPR_ejsh", "ADJF-SUPR_ajsh", "ADJF-SUPR_suppl", "ADJF-SUPR_nai", "ADJF-SUPR_slng", "NORM-ORPHOVAR",
+		"ADVB-COMP"}}
+
+	// ProfileDictionary reports the dictionary lemma of every form as is.
+	ProfileDictionary = Profile{Name: "dictionary"}
+)
+
+// Profiles lists the built-in profiles for lookup by name.
+var Profiles = []Profile{ProfileSearch, ProfileUD, ProfileDictionary}
+
+func ProfileByName(name string) (Profile, error) {
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("unknown lemmatization profile %q", name)
+}
+
+// WithProfile makes the Lemmatizer follow the links of the profile; the default
+// is ProfileSearch.
+func WithProfile(p Profile) Option {
+	return WithImportantLinks(p.Links...)
+}
+
+// LinkStep is a dictionary link from lemma From to lemma To, followed back
+// from To to From.
+type LinkStep struct {
+	Type string
+	From string
+	To   string
+}
+
+// LinkChain returns the links followed from the dictionary lemma of the form to
+// the lemma FormLemma reports, or nil for forms outside the dictionary.
+func (l *Lemmatizer) LinkChain(form Form) []LinkStep {
+	if form.LemmaIdx&userLemmaFlag != 0 || form.LemmaIdx == 0 {
+		return nil
+	}
+
+	dict := &l.base.Dictionary
+	var chain []LinkStep
+	lemma := dict.Lemmas[form.LemmaIdx]
+	for {
+		var best Link
+		maxScore := -1
+		for i := range lemma.LinkLen {
+			link := dict.Links[int(lemma.LinkIdx)+int(i)]
+			if !dict.importantLinks[link.Type] {
+				continue
+			}
+			if _, score := l.followLinks(dict.Lemmas[link.FromLemmaIdx]); score > maxScore {
+				maxScore = score
+				best = link
+			}
+		}
+		if maxScore < 0 {
+			return chain
+		}
+
+		from := dict.Lemmas[best.FromLemmaIdx]
+		chain = append(chain, LinkStep{Type: l.linkNames[best.Type], From: dict.exported(dict.lemmaText(from)), To: dict.exported(dict.lemmaText(lemma))})
+		lemma = from
+	}
+}
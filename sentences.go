@@ -0,0 +1,137 @@
+package nlp
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Sentence struct {
+	Start int
+	End   int
+}
+
+func (s Sentence) Tokens(tokens []Token) []Token {
+	return tokens[s.Start:s.End]
+}
+
+var nonTerminalAbbreviations = map[string]struct{}{
+	"г.": {}, "гг.": {}, "ул.": {}, "д.": {}, "пр.": {}, "просп.": {}, "пер.": {}, "пл.": {},
+	"им.": {}, "проф.": {}, "акад.": {}, "доц.": {}, "св.": {}, "ст.": {}, "см.": {}, "стр.": {},
+	"рис.": {}, "табл.": {}, "гл.": {}, "п.": {}, "пп.": {}, "т.": {}, "тт.": {}, "ч.": {}, "р.": {},
+	"обл.": {}, "с.": {}, "о.": {},
+	"тов.": {}, "mr.": {}, "mrs.": {}, "dr.": {},
+}
+
+func SplitSentences(tokens []Token) []Sentence {
+	var sentences []Sentence
+
+	start := 0
+	for i := 0; i < len(tokens); i++ {
+		terminal, strong := isSentenceTerminal(&tokens[i])
+		if !terminal {
+			continue
+		}
+
+		end := i + 1
+		for end < len(tokens) && isClosingPunct(&tokens[end]) {
+			end++
+		}
+
+		if end < len(tokens) && !startsSentence(tokens[end:], strong) {
+			i = end - 1
+			continue
+		}
+
+		sentences = append(sentences, Sentence{Start: start, End: end})
+		start = end
+		i = end - 1
+	}
+
+	if start < len(tokens) {
+		sentences = append(sentences, Sentence{Start: start, End: len(tokens)})
+	}
+
+	return sentences
+}
+
+func isSentenceTerminal(t *Token) (terminal bool, strong bool) {
+	text := t.Text()
+
+	switch t.tp {
+	case TokenPunct, TokenKeyword:
+		if isTerminalPunct(text) {
+			return true, true
+		}
+		if t.tp == TokenKeyword && strings.HasSuffix(text, ".") {
+			_, ok := nonTerminalAbbreviations[text]
+			return !ok, false
+		}
+	case TokenWord:
+		if len(t.parts) < 2 || t.parts[len(t.parts)-1].Type != TokenPunct || !strings.HasSuffix(text, ".") {
+			return false, false
+		}
+		if _, ok := nonTerminalAbbreviations[text]; ok {
+			return false, false
+		}
+		first := Token{tp: t.parts[0].Type, rawText: t.rawText, origText: t.origText, parts: t.parts[:1]}
+		return !first.isInitial(), false
+	}
+
+	return false, false
+}
+
+func isTerminalPunct(text string) bool {
+	if len(text) == 0 {
+		return false
+	}
+	for _, r := range text {
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			return false
+		}
+	}
+	return true
+}
+
+func isClosingPunct(t *Token) bool {
+	if t.tp != TokenPunct {
+		return false
+	}
+	for _, r := range t.Text() {
+		switch r {
+		case '»', '"', '”', '’', '\'', ')', ']':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isOpeningPunct(t *Token) bool {
+	if t.tp != TokenPunct && t.tp != TokenOther {
+		return false
+	}
+	for _, r := range t.Text() {
+		switch r {
+		case '«', '"', '“', '„', '\'', '(', '[', '—', '–', '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func startsSentence(tokens []Token, strong bool) bool {
+	for i := range tokens {
+		if isOpeningPunct(&tokens[i]) {
+			continue
+		}
+
+		r := tokens[i].firstSurfaceRune()
+		if unicode.IsUpper(r) {
+			return true
+		}
+		return strong && unicode.IsDigit(r)
+	}
+
+	return true
+}
@@ -0,0 +1,36 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sentenceTexts(text string) []string {
+	tokens := Tokenize(text, NewKeywords(DefaultKeywords))
+	var result []string
+	for _, s := range SplitSentences(tokens) {
+		sentence := s.Tokens(tokens)
+		result = append(result, text[sentence[0].Start():sentence[len(sentence)-1].End()])
+	}
+	return result
+}
+
+func TestSplitSentences(t *testing.T) {
+	assert.Equal(t, []string{"Мама мыла раму.", "Папа читал газету!", "Что дальше?"},
+		sentenceTexts("Мама мыла раму. Папа читал газету! Что дальше?"))
+
+	assert.Equal(t, []string{"Его написал А. С. Пушкин в г. Москве.", "Это было давно."},
+		sentenceTexts("Его написал А. С. Пушкин в г. Москве. Это было давно."))
+
+	assert.Equal(t, []string{"Число 3.14, т.е. пи, известно всем.", "Он ушёл... и вернулся.", "Ждали долго..."},
+		sentenceTexts("Число 3.14, т.е. пи, известно всем. Он ушёл... и вернулся. Ждали долго..."))
+
+	assert.Equal(t, []string{"«Привет!» — сказал он.", "«Пока!»", "Дверь закрылась."},
+		sentenceTexts("«Привет!» — сказал он. «Пока!» Дверь закрылась."))
+
+	assert.Equal(t, []string{"Купили хлеб, молоко и т.д.", "Потом пошли домой."},
+		sentenceTexts("Купили хлеб, молоко и т.д. Потом пошли домой."))
+
+	assert.Empty(t, SplitSentences(nil))
+}
@@ -2,15 +2,51 @@ package nlp
 
 type StopwordSet []string
 
+// Stopword sets hold normalized lemmas, so a single entry covers every
+// inflected form once tokens are lemmatized.
+
+// SearchStopwords is the conservative set for search indexing: prepositions,
+// conjunctions and particles only.
+var SearchStopwords = StopwordSet{
+	"без", "в", "во", "для", "до", "за", "из", "изо", "к", "ко", "на", "над", "надо", "о", "об", "обо", "от", "ото",
+	"по", "под", "подо", "при", "про", "с", "со", "у", "через",
+	"а", "и", "или", "либо", "но", "да", "зато", "однако", "что", "чтобы", "если", "хотя", "потому", "поэтому",
+	"также", "тоже", "ибо", "пока", "когда", "как", "будто", "словно", "чем",
+	"бы", "ведь", "вот", "вон", "даже", "же", "ж", "ли", "ль", "лишь", "не", "ни", "уж", "только", "разве", "неужели",
+}
+
+// GeneralStopwords adds pronouns, the copula and common adverbs.
+var GeneralStopwords = append(append(StopwordSet{}, SearchStopwords...),
+	"я", "ты", "он", "она", "оно", "мы", "вы", "они", "себя", "свой", "мой", "твой", "наш", "ваш", "его", "ее", "их",
+	"этот", "тот", "такой", "какой", "который", "чей", "весь", "все", "всякий", "каждый", "сам", "самый", "кто",
+	"никто", "ничто", "нечто", "некто", "кое-кто", "кое-что", "что-то", "кто-то", "это", "то",
+	"быть", "нет", "уже", "еще", "там", "тут", "здесь", "где", "куда", "откуда", "тогда", "так", "вообще",
+)
+
+// AggressiveStopwords also drops frequent verbs, adverbs and numerals that
+// carry little topical meaning.
+var AggressiveStopwords = append(append(StopwordSet{}, GeneralStopwords...),
+	"мочь", "хотеть", "стать", "делать", "сделать", "иметь", "говорить", "сказать", "являться", "должен",
+	"можно", "нужно", "нельзя", "очень", "более", "менее", "много", "мало", "сейчас", "теперь", "потом", "всегда",
+	"никогда", "иногда", "опять", "снова", "конечно", "например", "просто", "почти", "совсем", "кроме",
+	"один", "два", "три", "раз", "другой", "первый",
+)
+
+// DefaultStopwords is empty; pass one of the sets above to filter stopwords.
 var DefaultStopwords = StopwordSet{}
 
+// FunctionWordPOS lists the parts of speech dropped as function words.
+var FunctionWordPOS = []POS{ADP, CCONJ, SCONJ, PART, PRON}
+
 type Stopwords struct {
 	stopwords map[string]struct{}
+	pos       map[POS]struct{}
 }
 
 func NewStopwords(stopwordSets ...StopwordSet) *Stopwords {
 	s := Stopwords{
 		stopwords: map[string]struct{}{},
+		pos:       map[POS]struct{}{},
 	}
 
 	for _, set := range stopwordSets {
@@ -23,7 +59,34 @@ func NewStopwords(stopwordSets ...StopwordSet) *Stopwords {
 	return &s
 }
 
+// AddPOS makes every token with one of the given parts of speech a stopword.
+func (s *Stopwords) AddPOS(pos ...POS) *Stopwords {
+	for _, p := range pos {
+		s.pos[p] = struct{}{}
+	}
+	return s
+}
+
 func (s *Stopwords) IsStopword(word string) bool {
 	_, ok := s.stopwords[word]
 	return ok
 }
+
+// Match reports whether a token with the given lemma and part of speech is a
+// stopword; pass the surface text as lemma when no lemma is known.
+func (s *Stopwords) Match(lemma string, pos POS) bool {
+	if _, ok := s.pos[pos]; ok {
+		return true
+	}
+	return s.IsStopword(lemma)
+}
+
+func (s *Stopwords) Filter(tokens []AnalyzedToken) []AnalyzedToken {
+	result := make([]AnalyzedToken, 0, len(tokens))
+	for _, t := range tokens {
+		if !s.Match(t.Lemma, t.POS) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopwordSets(t *testing.T) {
+	for name, set := range map[string]StopwordSet{
+		"search":     SearchStopwords,
+		"general":    GeneralStopwords,
+		"aggressive": AggressiveStopwords,
+	} {
+		seen := map[string]bool{}
+		for _, w := range set {
+			assert.Equal(t, Normalize(w), w, "%s: %q is not normalized", name, w)
+			assert.False(t, seen[w], "%s: duplicate %q", name, w)
+			seen[w] = true
+		}
+	}
+
+	general := NewStopwords(GeneralStopwords)
+	for _, w := range SearchStopwords {
+		assert.True(t, general.IsStopword(w))
+	}
+	aggressive := NewStopwords(AggressiveStopwords)
+	for _, w := range GeneralStopwords {
+		assert.True(t, aggressive.IsStopword(w))
+	}
+	assert.False(t, NewStopwords(DefaultStopwords).IsStopword("который"))
+}
+
+func tokenLemmas(tokens []AnalyzedToken) []string {
+	var result []string
+	for _, t := range tokens {
+		result = append(result, t.Lemma)
+	}
+	return result
+}
+
+func TestStopwordsFilter(t *testing.T) {
+	l := newTestLemmatizer(t)
+	tokens := l.AnalyzeText("Он читает книги в Москве")
+
+	assert.Equal(t, []string{"он", "читать", "книга", "москва"}, tokenLemmas(NewStopwords(SearchStopwords).Filter(tokens)))
+	assert.Equal(t, []string{"читать", "книга", "москва"}, tokenLemmas(NewStopwords(GeneralStopwords).Filter(tokens)))
+	assert.Equal(t, []string{"читать", "книга", "москва"}, tokenLemmas(NewStopwords().AddPOS(FunctionWordPOS...).Filter(tokens)))
+
+	stopwords := NewStopwords(StopwordSet{"книга"})
+	assert.True(t, stopwords.Match("книга", NOUN))
+	assert.False(t, stopwords.Match("книги", NOUN))
+	assert.Equal(t, []string{"он", "читать", "в", "москва"}, tokenLemmas(stopwords.Filter(tokens)))
+}
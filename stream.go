@@ -0,0 +1,182 @@
+package nlp
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultChunkSize   = 64 * 1024
+	defaultMaxBuffer   = 1 << 20
+	defaultMaxSentence = 10000
+)
+
+// Tokenizer tokenizes text read from an io.Reader piece by piece in bounded
+// memory. Its output is identical to Tokenize on the whole text, offsets
+// included, except that a stretch of over a megabyte without whitespace is cut
+// before its last token, and a run of over 10000 tokens without a sentence end
+// is emitted by Sentences as a sentence of its own.
+type Tokenizer struct {
+	r           io.Reader
+	keywords    *Keywords
+	chunkSize   int
+	maxBuffer   int
+	maxSentence int
+	err         error
+}
+
+func NewTokenizer(r io.Reader, keywords *Keywords) *Tokenizer {
+	return &Tokenizer{
+		r:           r,
+		keywords:    keywords,
+		chunkSize:   defaultChunkSize,
+		maxBuffer:   defaultMaxBuffer,
+		maxSentence: defaultMaxSentence,
+	}
+}
+
+// Err returns the first non-EOF error from the reader.
+func (t *Tokenizer) Err() error {
+	return t.err
+}
+
+func (t *Tokenizer) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		var buf []byte
+		base := 0
+		chunk := make([]byte, t.chunkSize)
+
+		for {
+			n, err := t.r.Read(chunk)
+			// A new cut point needs new whitespace; look back for a rune split
+			// across reads.
+			newSpace := bytes.ContainsFunc(buf[max(0, len(buf)-utf8.UTFMax):], unicode.IsSpace) ||
+				bytes.ContainsFunc(chunk[:n], unicode.IsSpace)
+			buf = append(buf, chunk[:n]...)
+			if err != nil {
+				if err != io.EOF {
+					t.err = err
+				}
+				break
+			}
+			if !newSpace && len(buf) <= t.maxBuffer {
+				continue
+			}
+
+			text := string(buf[:completeRunes(buf)])
+			normText, offsets := normalizeWithOffsets(text)
+			tokens := split(normText, offsets, text, t.keywords)
+
+			var merged []Token
+			cut, ok := t.safeCut(tokens, len(normText))
+			if ok {
+				merged = mergeSplitTokens(tokens)
+			} else if len(buf) > t.maxBuffer {
+				cut = len(text)
+				if last := len(tokens) - 1; last >= 0 && tokens[last].parts[0].origStart > 0 {
+					cut = tokens[last].parts[0].origStart
+				}
+				merged = Tokenize(text[:cut], t.keywords)
+			} else {
+				continue
+			}
+
+			for _, token := range merged {
+				if token.Start() >= cut {
+					break
+				}
+				token.origBase = base
+				if !yield(token) {
+					return
+				}
+			}
+			buf = append(buf[:0], buf[cut:]...)
+			base += cut
+		}
+
+		for _, token := range Tokenize(string(buf), t.keywords) {
+			token.origBase = base
+			if !yield(token) {
+				return
+			}
+		}
+	}
+}
+
+// safeCut finds a whitespace run that tokens never cross and after which the
+// text is complete enough for every merge before it to be decided: a second
+// whitespace run must follow, and no keyword may still be growing into it.
+// The returned offset points at the start of the run in the original text.
+func (t *Tokenizer) safeCut(tokens []Token, normLen int) (int, bool) {
+	limit := normLen - t.keywords.maxLen - 1
+	next := -1
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].tp != TokenSpace {
+			continue
+		}
+		if tokens[i].parts[0].end >= limit {
+			continue
+		}
+		if next >= 0 {
+			return tokens[i].parts[0].origStart, true
+		}
+		next = i
+	}
+	return 0, false
+}
+
+func completeRunes(buf []byte) int {
+	for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(buf[i]) {
+			if utf8.FullRune(buf[i:]) {
+				return len(buf)
+			}
+			return i
+		}
+	}
+	return len(buf)
+}
+
+// Sentences groups the tokens into sentences exactly as SplitSentences does,
+// emitting each one as soon as the following tokens settle its end.
+func (t *Tokenizer) Sentences() iter.Seq[[]Token] {
+	return func(yield func([]Token) bool) {
+		var pending []Token
+		terminal := false
+
+		for token := range t.Tokens() {
+			if len(pending) >= t.maxSentence {
+				for _, s := range SplitSentences(pending) {
+					if !yield(s.Tokens(pending)) {
+						return
+					}
+				}
+				pending = nil
+				terminal = false
+			}
+			pending = append(pending, token)
+			if terminal && !isOpeningPunct(&token) && !isClosingPunct(&token) {
+				sentences := SplitSentences(pending)
+				for _, s := range sentences[:len(sentences)-1] {
+					if !yield(s.Tokens(pending)) {
+						return
+					}
+				}
+				pending = append([]Token(nil), sentences[len(sentences)-1].Tokens(pending)...)
+				terminal = false
+			}
+			if ok, _ := isSentenceTerminal(&pending[len(pending)-1]); ok {
+				terminal = true
+			}
+		}
+
+		for _, s := range SplitSentences(pending) {
+			if !yield(s.Tokens(pending)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package nlp
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamText = `  Мама мыла раму.  В 988 г. н. э. Русь приняла христианство, т. е. всё изменилось!
+«Кто-то пришёл?» — спросил А. С. Пушкин. Ул. Ленина, д. 5.
+Число 3.14 и 12:30 — это 100% правда :) Ёлка ударе́ние  э́то
+Он сказал: «Привет». (Потом ушёл.) Т.е. всё ок?! 2024 год... и т.д. и т. п.
+
+Конец`
+
+type streamedToken struct {
+	Text    string
+	Surface string
+	Start   int
+	End     int
+	Type    TokenType
+	Parts   []TokenPart
+}
+
+func describeTokens(tokens []Token) []streamedToken {
+	result := make([]streamedToken, len(tokens))
+	for i := range tokens {
+		t := &tokens[i]
+		result[i] = streamedToken{t.Text(), t.Surface(), t.Start(), t.End(), t.Type(), t.Parts()}
+	}
+	return result
+}
+
+func streamTokens(tokenizer *Tokenizer) []Token {
+	var tokens []Token
+	for t := range tokenizer.Tokens() {
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func TestTokenizerMatchesTokenize(t *testing.T) {
+	keywords := NewKeywords(DefaultKeywords)
+	texts := []string{streamText, strings.Repeat(streamText+" ", 20), "", "   ", "слово", "н. э."}
+
+	for _, text := range texts {
+		expected := describeTokens(Tokenize(text, keywords))
+		for _, chunkSize := range []int{1, 2, 3, 5, 7, 16, 64, 4096} {
+			tokenizer := NewTokenizer(strings.NewReader(text), keywords)
+			tokenizer.chunkSize = chunkSize
+			assert.Equal(t, expected, describeTokens(streamTokens(tokenizer)), "chunk size %d", chunkSize)
+			assert.NoError(t, tokenizer.Err())
+		}
+
+		tokenizer := NewTokenizer(iotest.OneByteReader(strings.NewReader(text)), keywords)
+		assert.Equal(t, expected, describeTokens(streamTokens(tokenizer)))
+	}
+}
+
+func TestTokenizerSentences(t *testing.T) {
+	keywords := NewKeywords(DefaultKeywords)
+	text := strings.Repeat(streamText+" ", 5)
+
+	tokens := Tokenize(text, keywords)
+	var expected [][]streamedToken
+	for _, s := range SplitSentences(tokens) {
+		expected = append(expected, describeTokens(s.Tokens(tokens)))
+	}
+
+	for _, chunkSize := range []int{1, 7, 64, 4096} {
+		tokenizer := NewTokenizer(strings.NewReader(text), keywords)
+		tokenizer.chunkSize = chunkSize
+
+		var actual [][]streamedToken
+		for sentence := range tokenizer.Sentences() {
+			actual = append(actual, describeTokens(sentence))
+		}
+		assert.Equal(t, expected, actual, "chunk size %d", chunkSize)
+	}
+}
+
+func TestTokenizerStopEarly(t *testing.T) {
+	tokenizer := NewTokenizer(strings.NewReader(streamText), NewKeywords(DefaultKeywords))
+	tokenizer.chunkSize = 8
+
+	count := 0
+	for range tokenizer.Tokens() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestTokenizerError(t *testing.T) {
+	errRead := errors.New("read failed")
+	r := io.MultiReader(strings.NewReader("мама мыла"), iotest.ErrReader(errRead))
+	tokenizer := NewTokenizer(r, NewKeywords(DefaultKeywords))
+
+	tokens := streamTokens(tokenizer)
+	require.ErrorIs(t, tokenizer.Err(), errRead)
+	assert.Len(t, tokens, 2)
+}
+
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestTokenizerBoundedBuffer(t *testing.T) {
+	keywords := NewKeywords(DefaultKeywords)
+	text := strings.Repeat("слово,", 2000) + " конец"
+	r := &countingReader{r: strings.NewReader(text)}
+	tokenizer := NewTokenizer(r, keywords)
+	tokenizer.chunkSize = 64
+	tokenizer.maxBuffer = 512
+
+	var tokens []Token
+	for token := range tokenizer.Tokens() {
+		if len(tokens) == 0 {
+			assert.Less(t, r.read, 1024)
+		}
+		tokens = append(tokens, token)
+	}
+
+	var sb strings.Builder
+	for i := range tokens {
+		assert.Equal(t, tokens[i].Surface(), text[tokens[i].Start():tokens[i].End()])
+		sb.WriteString(tokens[i].Surface())
+	}
+	assert.Equal(t, strings.ReplaceAll(text, " ", ""), sb.String())
+	assert.Equal(t, describeTokens(Tokenize(text, keywords)), describeTokens(tokens))
+
+	text = strings.Repeat("а", 3000)
+	tokenizer = NewTokenizer(strings.NewReader(text), keywords)
+	tokenizer.chunkSize = 64
+	tokenizer.maxBuffer = 512
+	tokens = streamTokens(tokenizer)
+	require.Greater(t, len(tokens), 1)
+	assert.Equal(t, 0, tokens[0].Start())
+	assert.Equal(t, len(text), tokens[len(tokens)-1].End())
+}
+
+func TestTokenizerLongSentence(t *testing.T) {
+	keywords := NewKeywords(DefaultKeywords)
+	text := strings.Repeat("мама мыла раму ", 20) + "и ушла. Конец"
+	tokenizer := NewTokenizer(strings.NewReader(text), keywords)
+	tokenizer.maxSentence = 10
+
+	var tokens []Token
+	for sentence := range tokenizer.Sentences() {
+		assert.LessOrEqual(t, len(sentence), 10)
+		tokens = append(tokens, sentence...)
+	}
+	assert.Equal(t, describeTokens(Tokenize(text, keywords)), describeTokens(tokens))
+}
@@ -0,0 +1,199 @@
+package nlp
+
+import "fmt"
+
+type TaggerTrainer struct {
+	Weight int
+
+	tagger    StatisticalTagger
+	baseTags  int
+	baseWords int
+	words     map[string]struct{}
+}
+
+func NewTaggerTrainer(base *StatisticalTagger) *TaggerTrainer {
+	t := &TaggerTrainer{
+		Weight: 1,
+		tagger: StatisticalTagger{
+			TransitionCounts: map[FEATS]map[FEATS]int{},
+			TagTotalCounts:   map[FEATS]int{},
+			TrigramCounts:    map[TagPair]map[FEATS]int{},
+			TagPairCounts:    map[TagPair]int{},
+			Alpha:            0.25,
+		},
+		words: map[string]struct{}{},
+	}
+
+	if base != nil {
+		for prev, row := range base.TransitionCounts {
+			t.tagger.TransitionCounts[prev] = make(map[FEATS]int, len(row))
+			for curr, count := range row {
+				t.tagger.TransitionCounts[prev][curr] = count
+			}
+		}
+		for tag, count := range base.TagTotalCounts {
+			t.tagger.TagTotalCounts[tag] = count
+		}
+		for pair, row := range base.TrigramCounts {
+			t.tagger.TrigramCounts[pair] = make(map[FEATS]int, len(row))
+			for curr, count := range row {
+				t.tagger.TrigramCounts[pair][curr] = count
+			}
+		}
+		for pair, count := range base.TagPairCounts {
+			t.tagger.TagPairCounts[pair] = count
+		}
+		if base.Alpha > 0 {
+			t.tagger.Alpha = base.Alpha
+		}
+		t.baseTags = base.UniqueTags
+		t.baseWords = base.UniqueWords
+	}
+
+	return t
+}
+
+func (t *TaggerTrainer) AddSentence(forms []string, tags []FEATS) error {
+	if len(forms) != len(tags) {
+		return fmt.Errorf("sentence has %d forms and %d tags", len(forms), len(tags))
+	}
+
+	tokens := CreateTokens(forms)
+	prev := startTag & BigramMask
+	prev2 := prev
+	t.tagger.TagTotalCounts[prev] += t.Weight
+
+	for i, tag := range tags {
+		switch tokens[i].Type() {
+		case TokenWord, TokenNumber, TokenKeyword:
+		default:
+			continue
+		}
+
+		tag &= BigramMask
+		row := t.tagger.TransitionCounts[prev]
+		if row == nil {
+			row = map[FEATS]int{}
+			t.tagger.TransitionCounts[prev] = row
+		}
+		row[tag] += t.Weight
+		t.tagger.TagTotalCounts[tag] += t.Weight
+
+		pair := TagPair{Prev2: prev2, Prev: prev}
+		trigrams := t.tagger.TrigramCounts[pair]
+		if trigrams == nil {
+			trigrams = map[FEATS]int{}
+			t.tagger.TrigramCounts[pair] = trigrams
+		}
+		trigrams[tag] += t.Weight
+		t.tagger.TagPairCounts[pair] += t.Weight
+
+		t.words[tokens[i].Text()] = struct{}{}
+		prev2, prev = prev, tag
+	}
+
+	return nil
+}
+
+func (t *TaggerTrainer) AddCoNLLU(sentences []CoNLLUSentence) error {
+	for _, s := range sentences {
+		forms := make([]string, len(s.Tokens))
+		tags := make([]FEATS, len(s.Tokens))
+		for i, tok := range s.Tokens {
+			forms[i] = tok.Form
+			tags[i] = tok.FEATS
+		}
+		if err := t.AddSentence(forms, tags); err != nil {
+			return fmt.Errorf("sentence %s: %w", s.ID, err)
+		}
+	}
+	return nil
+}
+
+func (t *TaggerTrainer) Tagger() StatisticalTagger {
+	tagger := t.tagger
+
+	tagger.TransitionCounts = make(map[FEATS]map[FEATS]int, len(t.tagger.TransitionCounts))
+	for prev, row := range t.tagger.TransitionCounts {
+		tagger.TransitionCounts[prev] = make(map[FEATS]int, len(row))
+		for curr, count := range row {
+			tagger.TransitionCounts[prev][curr] = count
+		}
+	}
+	tagger.TagTotalCounts = make(map[FEATS]int, len(t.tagger.TagTotalCounts))
+	for tag, count := range t.tagger.TagTotalCounts {
+		tagger.TagTotalCounts[tag] = count
+	}
+	tagger.TrigramCounts = make(map[TagPair]map[FEATS]int, len(t.tagger.TrigramCounts))
+	for pair, row := range t.tagger.TrigramCounts {
+		tagger.TrigramCounts[pair] = make(map[FEATS]int, len(row))
+		for curr, count := range row {
+			tagger.TrigramCounts[pair][curr] = count
+		}
+	}
+	tagger.TagPairCounts = make(map[TagPair]int, len(t.tagger.TagPairCounts))
+	for pair, count := range t.tagger.TagPairCounts {
+		tagger.TagPairCounts[pair] = count
+	}
+	tagger.TrigramLambdas = deletedInterpolation(&tagger)
+
+	tagger.UniqueTags = max(t.baseTags, len(tagger.TagTotalCounts))
+	// New words are not checked against the base vocabulary, so UniqueWords is an upper bound.
+	tagger.UniqueWords = t.baseWords + len(t.words)
+
+	return tagger
+}
+
+func deletedInterpolation(tagger *StatisticalTagger) [3]float64 {
+	total := 0
+	for tag, count := range tagger.TagTotalCounts {
+		if tag != startTag&BigramMask {
+			total += count
+		}
+	}
+
+	ratio := func(num, denom int) float64 {
+		if denom <= 1 {
+			return 0
+		}
+		return float64(num-1) / float64(denom-1)
+	}
+
+	var lambdas [3]float64
+	for pair, row := range tagger.TrigramCounts {
+		for tag, count := range row {
+			candidates := [3]float64{
+				ratio(tagger.TagTotalCounts[tag], total),
+				ratio(tagger.TransitionCounts[pair.Prev][tag], tagger.TagTotalCounts[pair.Prev]),
+				ratio(count, tagger.TagPairCounts[pair]),
+			}
+			best := 0
+			for i := range candidates {
+				if candidates[i] > candidates[best] {
+					best = i
+				}
+			}
+			lambdas[best] += float64(count)
+		}
+	}
+
+	sum := lambdas[0] + lambdas[1] + lambdas[2]
+	if sum == 0 {
+		return [3]float64{0.1, 0.3, 0.6}
+	}
+	for i := range lambdas {
+		lambdas[i] /= sum
+	}
+
+	// Unseen trigrams get probability only from the unigram and bigram terms,
+	// so those must keep some weight even when the trigram term always wins.
+	lambdas[0] = max(lambdas[0], minInterpolationWeight)
+	lambdas[1] = max(lambdas[1], minInterpolationWeight)
+	sum = lambdas[0] + lambdas[1] + lambdas[2]
+	for i := range lambdas {
+		lambdas[i] /= sum
+	}
+	return lambdas
+}
+
+const minInterpolationWeight = 0.01
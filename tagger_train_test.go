@@ -0,0 +1,46 @@
+package nlp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaggerTrainer(t *testing.T) {
+	gold, err := ReadCoNLLU(strings.NewReader(evalGold))
+	require.NoError(t, err)
+
+	trainer := NewTaggerTrainer(nil)
+	require.NoError(t, trainer.AddCoNLLU(gold))
+	tagger := trainer.Tagger()
+
+	start := startTag & BigramMask
+	nounNom := FEATS(0).SetPOS(NOUN).SetCase(Nom).SetGender(Fem).SetNumber(Sing)
+	verbFin := FEATS(0).SetPOS(VERB).SetVerbForm(Fin).SetGender(Fem).SetNumber(Sing)
+
+	assert.Equal(t, 2, tagger.TagTotalCounts[start])
+	assert.Equal(t, 1, tagger.TransitionCounts[start][nounNom])
+	assert.Equal(t, 1, tagger.TransitionCounts[nounNom][verbFin])
+	assert.Equal(t, 1, tagger.TagTotalCounts[verbFin])
+	assert.Equal(t, 0, tagger.TagTotalCounts[FEATS(0).SetPOS(PUNCT)])
+	assert.Equal(t, 5, tagger.UniqueWords)
+	assert.Equal(t, 6, tagger.UniqueTags)
+
+	merged := NewTaggerTrainer(&tagger)
+	merged.Weight = 3
+	require.NoError(t, merged.AddSentence([]string{"Мама", "мыла"}, []FEATS{nounNom, verbFin}))
+	require.Error(t, merged.AddSentence([]string{"Мама"}, nil))
+	mergedTagger := merged.Tagger()
+
+	assert.Equal(t, 4, mergedTagger.TransitionCounts[nounNom][verbFin])
+	assert.Equal(t, 5, mergedTagger.TagTotalCounts[start])
+	assert.Equal(t, 1, tagger.TransitionCounts[nounNom][verbFin])
+
+	data := buildFixtureData(fixtureForms, fixtureLinks, nil)
+	data.Dictionary.Tagger = mergedTagger
+	l, err := NewLemmatizer(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"мама", "мыть", "рама"}, l.LemmatizeText("мама мыла раму"))
+}
@@ -18,16 +18,42 @@ const (
 	TokenKeyword
 )
 
+func (t TokenType) String() string {
+	switch t {
+	case TokenUnknown:
+		return "UNKNOWN"
+	case TokenWord:
+		return "WORD"
+	case TokenNumber:
+		return "NUMBER"
+	case TokenSym:
+		return "SYM"
+	case TokenPunct:
+		return "PUNCT"
+	case TokenSpace:
+		return "SPACE"
+	case TokenOther:
+		return "OTHER"
+	case TokenKeyword:
+		return "KEYWORD"
+	}
+	return "ERROR"
+}
+
 type tokenPart struct {
-	start int
-	end   int
-	Type  TokenType
+	start     int
+	end       int
+	origStart int
+	origEnd   int
+	Type      TokenType
 }
 
 type Token struct {
-	tp      TokenType
-	rawText string
-	parts   []tokenPart
+	tp       TokenType
+	rawText  string
+	origText string
+	origBase int
+	parts    []tokenPart
 
 	partsBuf [3]tokenPart
 }
@@ -36,15 +62,33 @@ func (t *Token) Text() string {
 	return t.rawText[t.parts[0].start:t.parts[len(t.parts)-1].end]
 }
 
+func (t *Token) Surface() string {
+	return t.origText[t.parts[0].origStart:t.parts[len(t.parts)-1].origEnd]
+}
+
+func (t *Token) Start() int {
+	return t.origBase + t.parts[0].origStart
+}
+
+func (t *Token) End() int {
+	return t.origBase + t.parts[len(t.parts)-1].origEnd
+}
+
 type TokenPart struct {
-	Text string
-	Type TokenType
+	Text    string
+	Surface string
+	Start   int
+	End     int
+	Type    TokenType
 }
 
 func (t *Token) Parts() []TokenPart {
 	parts := make([]TokenPart, len(t.parts))
 	for i, p := range t.parts {
 		parts[i].Text = t.rawText[p.start:p.end]
+		parts[i].Surface = t.origText[p.origStart:p.origEnd]
+		parts[i].Start = t.origBase + p.origStart
+		parts[i].End = t.origBase + p.origEnd
 		parts[i].Type = p.Type
 	}
 	return parts
@@ -54,9 +98,22 @@ func (t *Token) Type() TokenType {
 	return t.tp
 }
 
+func (t *Token) firstSurfaceRune() rune {
+	r, _ := utf8.DecodeRuneInString(t.Surface())
+	return r
+}
+
+func (t *Token) isInitial() bool {
+	surface := t.Surface()
+	return utf8.RuneCountInString(surface) == 1 && unicode.IsUpper(t.firstSurfaceRune())
+}
+
 func Tokenize(text string, keywords *Keywords) []Token {
-	tokens := split(Normalize(text), keywords)
+	normText, offsets := normalizeWithOffsets(text)
+	return mergeSplitTokens(split(normText, offsets, text, keywords))
+}
 
+func mergeSplitTokens(tokens []Token) []Token {
 	tokens = mergeNumbers(tokens)
 	tokens = mergeHyphenatedWords(tokens)
 	tokens = mergeAbbreviationsAdvanced(tokens)
@@ -99,17 +156,18 @@ func CreateTokens(words []string) []Token {
 			tp = TokenWord
 		}
 
-		normw := Normalize(w)
+		normw, offsets := normalizeWithOffsets(w)
 		result[i].rawText = normw
+		result[i].origText = w
 		result[i].parts = result[i].partsBuf[:1]
-		result[i].parts[0] = tokenPart{start: 0, end: len(normw), Type: tp}
+		result[i].parts[0] = tokenPart{start: 0, end: len(normw), origStart: offsets[0], origEnd: offsets[len(normw)], Type: tp}
 		result[i].tp = tp
 	}
 
 	return result
 }
 
-func split(text string, keywords *Keywords) []Token {
+func split(text string, offsets []int, origText string, keywords *Keywords) []Token {
 	tokens := make([]Token, 32)
 	numTokens := 0
 	currTokenType := TokenUnknown
@@ -123,9 +181,11 @@ func split(text string, keywords *Keywords) []Token {
 			tokens = newTokens
 		}
 		tokens[numTokens].rawText = text
+		tokens[numTokens].origText = origText
 		tokens[numTokens].tp = currTokenType
 		tokens[numTokens].parts = tokens[numTokens].partsBuf[:0]
-		tokens[numTokens].parts = append(tokens[numTokens].parts, tokenPart{start: start, end: end, Type: currTokenType})
+		tokens[numTokens].parts = append(tokens[numTokens].parts, tokenPart{start: start, end: end,
+			origStart: offsets[start], origEnd: offsets[end], Type: currTokenType})
 		numTokens++
 		currTokenStart = end
 	}
@@ -200,6 +260,8 @@ func mergeTokens(tokens []Token, res *Token) {
 		*res = Token{}
 	}
 	res.rawText = tokens[0].rawText
+	res.origText = tokens[0].origText
+	res.origBase = tokens[0].origBase
 	res.partsBuf = tokens[0].partsBuf
 	if len(tokens[0].parts) <= len(res.partsBuf) {
 		res.parts = res.partsBuf[:len(tokens[0].parts)]
@@ -281,7 +343,7 @@ func mergeAbbreviationsAdvanced(tokens []Token) []Token {
 	for i < len(tokens) {
 		if tokens[i].tp == TokenWord {
 			if i+3 < len(tokens) && tokens[i+1].Text() == "." {
-				if (tokens[i+2].tp == TokenPunct && tokens[i+2].Text() != ".") || unicode.IsUpper([]rune(tokens[i].Text())[0]) {
+				if (tokens[i+2].tp == TokenPunct && tokens[i+2].Text() != ".") || tokens[i].isInitial() {
 					mergeTokens(tokens[i:i+2], &tokens[currToken])
 					tokens[currToken].tp = TokenWord
 					currToken++
@@ -289,7 +351,7 @@ func mergeAbbreviationsAdvanced(tokens []Token) []Token {
 					continue
 				}
 				if tokens[i+2].tp == TokenSpace {
-					if tokens[i+3].tp == TokenWord && unicode.IsLower([]rune(tokens[i+3].Text())[0]) {
+					if tokens[i+3].tp == TokenWord && unicode.IsLower(tokens[i+3].firstSurfaceRune()) {
 						mergeTokens(tokens[i:i+2], &tokens[currToken])
 						tokens[currToken].tp = TokenWord
 						currToken++
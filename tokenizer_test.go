@@ -0,0 +1,42 @@
+package nlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenOffsets(t *testing.T) {
+	text := "  Ёлка стои́т, т.е. 3.14 кг — Кто-то из г. Москвы."
+	tokens := Tokenize(text, NewKeywords(DefaultKeywords))
+
+	var texts, surfaces []string
+	for _, tok := range tokens {
+		texts = append(texts, tok.Text())
+		surfaces = append(surfaces, tok.Surface())
+		assert.Equal(t, tok.Surface(), text[tok.Start():tok.End()])
+	}
+
+	assert.Equal(t, []string{"елка", "стоит", ",", "т.е.", "3.14", "кг", "—", "кто-то", "из", "г.", "москвы", "."}, texts)
+	assert.Equal(t, []string{"Ёлка", "стои́т", ",", "т.е.", "3.14", "кг", "—", "Кто-то", "из", "г.", "Москвы", "."}, surfaces)
+
+	parts := tokens[7].Parts()
+	assert.Len(t, parts, 3)
+	assert.Equal(t, "Кто", parts[0].Surface)
+	assert.Equal(t, "кто", parts[0].Text)
+	assert.Equal(t, "-", parts[1].Surface)
+	assert.Equal(t, "то", parts[2].Text)
+	assert.Equal(t, tokens[7].Start(), parts[0].Start)
+	assert.Equal(t, tokens[7].End(), parts[2].End)
+}
+
+func TestCreateTokensOffsets(t *testing.T) {
+	tokens := CreateTokens([]string{"Ёж", " Дом "})
+
+	assert.Equal(t, "еж", tokens[0].Text())
+	assert.Equal(t, "Ёж", tokens[0].Surface())
+	assert.Equal(t, "дом", tokens[1].Text())
+	assert.Equal(t, "Дом", tokens[1].Surface())
+	assert.Equal(t, 1, tokens[1].Start())
+	assert.Equal(t, 7, tokens[1].End())
+}
@@ -0,0 +1,278 @@
+package nlp
+
+import "math"
+
+func (l *Lemmatizer) trigramLogProb(prev2Tag, prevTag, currentTag FEATS) float64 {
+	tagger := &l.base.Dictionary.Tagger
+	prev2Tag, prevTag, currentTag = prev2Tag&TrigramMask, prevTag&TrigramMask, currentTag&TrigramMask
+
+	uniqueTags := float64(tagger.UniqueTags)
+	alpha := l.alpha
+
+	p1 := (float64(tagger.TagTotalCounts[currentTag]) + alpha) / (float64(l.unigramTotal) + alpha*uniqueTags)
+	p2 := (float64(tagger.TransitionCounts[prevTag][currentTag]) + alpha) /
+		(float64(tagger.TagTotalCounts[prevTag]) + alpha*uniqueTags)
+
+	p3 := 0.0
+	pair := TagPair{Prev2: prev2Tag, Prev: prevTag}
+	if denom := tagger.TagPairCounts[pair]; denom > 0 {
+		p3 = float64(tagger.TrigramCounts[pair][currentTag]) / float64(denom)
+	}
+
+	lambdas := tagger.TrigramLambdas
+	return math.Log(lambdas[0]*p1 + lambdas[1]*p2 + lambdas[2]*p3)
+}
+
+type trigramStep struct {
+	logProb float64
+	backPtr int
+}
+
+func (l *Lemmatizer) viterbiTrigram(sentence []Word) []Form {
+	if len(sentence) == 0 {
+		return nil
+	}
+
+	// Words without options get a zero Form and split the sentence into runs
+	// decoded on their own.
+	result := make([]Form, len(sentence))
+	start := 0
+	for i := 0; i <= len(sentence); i++ {
+		if i < len(sentence) && len(sentence[i].Options) > 0 {
+			continue
+		}
+		if start < i {
+			copy(result[start:i], l.viterbiTrigramRun(sentence[start:i]))
+		}
+		start = i + 1
+	}
+	return result
+}
+
+func (l *Lemmatizer) viterbiTrigramRun(sentence []Word) []Form {
+	n := len(sentence)
+
+	emissions := make([][]float64, n)
+	for i, w := range sentence {
+		emissions[i] = make([]float64, len(w.Options))
+		for j, f := range w.Options {
+			emissions[i][j] = l.emissionLogProb(f.FEATS, w)
+		}
+	}
+
+	// dp[i][a*len(Options[i])+b] holds the best path ending with option a at i-1 and option b at i.
+	dp := make([][]trigramStep, n)
+	first := sentence[0].Options
+	dp[0] = make([]trigramStep, len(first))
+	for b, f := range first {
+		dp[0][b] = trigramStep{logProb: l.trigramLogProb(startTag, startTag, f.FEATS) + emissions[0][b], backPtr: -1}
+	}
+
+	for i := 1; i < n; i++ {
+		prev := sentence[i-1].Options
+		curr := sentence[i].Options
+		dp[i] = make([]trigramStep, len(prev)*len(curr))
+
+		for a, prevForm := range prev {
+			for b, currForm := range curr {
+				coeff := transitionCoeff(prevForm.FEATS, currForm.FEATS)
+				best := trigramStep{logProb: -math.MaxFloat64, backPtr: 0}
+
+				if i == 1 {
+					best.logProb = dp[0][a].logProb + l.trigramLogProb(startTag, prevForm.FEATS, currForm.FEATS)*coeff
+				} else {
+					prev2 := sentence[i-2].Options
+					for c, prev2Form := range prev2 {
+						score := dp[i-1][c*len(prev)+a].logProb + l.trigramLogProb(prev2Form.FEATS, prevForm.FEATS, currForm.FEATS)*coeff
+						if score > best.logProb {
+							best = trigramStep{logProb: score, backPtr: c}
+						}
+					}
+				}
+
+				best.logProb += emissions[i][b]
+				dp[i][a*len(curr)+b] = best
+			}
+		}
+	}
+
+	result := make([]Form, n)
+	if n == 1 {
+		best := 0
+		for b := range dp[0] {
+			if dp[0][b].logProb > dp[0][best].logProb {
+				best = b
+			}
+		}
+		result[0] = first[best]
+		return result
+	}
+
+	last := sentence[n-1].Options
+	bestIdx := 0
+	for idx := range dp[n-1] {
+		if dp[n-1][idx].logProb > dp[n-1][bestIdx].logProb {
+			bestIdx = idx
+		}
+	}
+
+	a, b := bestIdx/len(last), bestIdx%len(last)
+	for i := n - 1; i >= 1; i-- {
+		result[i] = sentence[i].Options[b]
+		result[i-1] = sentence[i-1].Options[a]
+		c := dp[i][a*len(sentence[i].Options)+b].backPtr
+		a, b = c, a
+	}
+
+	return result
+}
+
+// trigramLattice scores the states of the trigram model over a prepared
+// scratch: a state at word i is a pair of options of words i-1 and i, indexed
+// a*width(i)+b, with a single start option before the first word.
+type trigramLattice struct {
+	l *Lemmatizer
+	s *viterbiScratch
+}
+
+func (t trigramLattice) width(i int) int {
+	if i < 0 {
+		return 1
+	}
+	return t.s.offsets[i+1] - t.s.offsets[i]
+}
+
+func (t trigramLattice) tag(i, option int) FEATS {
+	if i < 0 {
+		return startTag
+	}
+	return t.s.tags[t.s.offsets[i]+option]
+}
+
+// step scores moving from state (c, a) at word i-1 to state (a, b) at word i.
+func (t trigramLattice) step(i, c, a, b int) float64 {
+	prev2, prev, curr := t.tag(i-2, c), t.tag(i-1, a), t.tag(i, b)
+	coeff := 1.0
+	if i > 0 {
+		coeff = transitionCoeff(prev, curr)
+	}
+	return t.l.trigramLogProb(prev2, prev, curr)*coeff + t.s.emission[t.s.offsets[i]+b]
+}
+
+func (l *Lemmatizer) nbestTrigram(sentence []Word, n int) []TagPath {
+	s := viterbiScratchPool.Get().(*viterbiScratch)
+	defer viterbiScratchPool.Put(s)
+	s.prepare(l.scores, sentence)
+	t := trigramLattice{l: l, s: s}
+
+	entries := make([][][]nbestEntry, len(sentence))
+	entries[0] = make([][]nbestEntry, t.width(0))
+	for b := range t.width(0) {
+		entries[0][b] = []nbestEntry{{logProb: t.step(0, 0, 0, b), prev: -1}}
+	}
+
+	var buf []nbestEntry
+	for i := 1; i < len(sentence); i++ {
+		w := t.width(i)
+		entries[i] = make([][]nbestEntry, t.width(i-1)*w)
+		for a := range t.width(i - 1) {
+			for b := range w {
+				buf = buf[:0]
+				for c := range t.width(i - 2) {
+					prev := c*t.width(i-1) + a
+					step := t.step(i, c, a, b)
+					for r, e := range entries[i-1][prev] {
+						buf = append(buf, nbestEntry{logProb: e.logProb + step, prev: int32(prev), rank: int32(r)})
+					}
+				}
+				entries[i][a*w+b] = topEntries(buf, n)
+			}
+		}
+	}
+
+	last := len(sentence) - 1
+	buf = buf[:0]
+	for state, list := range entries[last] {
+		for r, e := range list {
+			buf = append(buf, nbestEntry{logProb: e.logProb, prev: int32(state), rank: int32(r)})
+		}
+	}
+
+	final := topEntries(buf, n)
+	paths := make([]TagPath, len(final))
+	for pi, f := range final {
+		forms := make([]Form, len(sentence))
+		state, r := int(f.prev), int(f.rank)
+		for i := last; i >= 0; i-- {
+			b := state % t.width(i)
+			forms[i] = sentence[i].Options[s.formIdx[s.offsets[i]+b]]
+			e := entries[i][state][r]
+			state, r = int(e.prev), int(e.rank)
+		}
+		paths[pi] = TagPath{Forms: forms, LogProb: f.logProb}
+	}
+
+	return paths
+}
+
+func (l *Lemmatizer) marginalsTrigram(sentence []Word) [][]float64 {
+	s := viterbiScratchPool.Get().(*viterbiScratch)
+	defer viterbiScratchPool.Put(s)
+	s.prepare(l.scores, sentence)
+	t := trigramLattice{l: l, s: s}
+
+	n := len(sentence)
+	forward := make([][]float64, n)
+	backward := make([][]float64, n)
+	terms := make([]float64, 0, 16)
+
+	forward[0] = make([]float64, t.width(0))
+	for b := range t.width(0) {
+		forward[0][b] = t.step(0, 0, 0, b)
+	}
+	for i := 1; i < n; i++ {
+		w := t.width(i)
+		forward[i] = make([]float64, t.width(i-1)*w)
+		for a := range t.width(i - 1) {
+			for b := range w {
+				terms = terms[:0]
+				for c := range t.width(i - 2) {
+					terms = append(terms, forward[i-1][c*t.width(i-1)+a]+t.step(i, c, a, b))
+				}
+				forward[i][a*w+b] = logSumExp(terms)
+			}
+		}
+	}
+
+	backward[n-1] = make([]float64, len(forward[n-1]))
+	for i := n - 2; i >= 0; i-- {
+		w, next := t.width(i), t.width(i+1)
+		backward[i] = make([]float64, len(forward[i]))
+		for c := range t.width(i - 1) {
+			for a := range w {
+				terms = terms[:0]
+				for b := range next {
+					terms = append(terms, t.step(i+1, c, a, b)+backward[i+1][a*next+b])
+				}
+				backward[i][c*w+a] = logSumExp(terms)
+			}
+		}
+	}
+
+	logZ := logSumExp(forward[n-1])
+
+	result := make([][]float64, n)
+	for i, word := range sentence {
+		result[i] = make([]float64, len(word.Options))
+		w := t.width(i)
+		for b := range w {
+			terms = terms[:0]
+			for a := range t.width(i - 1) {
+				terms = append(terms, forward[i][a*w+b]+backward[i][a*w+b])
+			}
+			shareTagPosterior(result[i], word.Options, t.tag(i, b), math.Exp(logSumExp(terms)-logZ))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,123 @@
+package nlp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrigramLemmatizer(t testing.TB) *Lemmatizer {
+	t.Helper()
+
+	data := buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences)
+	trainer := NewTaggerTrainer(&data.Dictionary.Tagger)
+	require.NoError(t, trainer.AddSentence([]string{"в", "новом", "доме"},
+		[]FEATS{FEATS(0).SetPOS(ADP), adj(Masc, Loc, Sing), noun(Masc, Loc, Sing)}))
+	require.NoError(t, trainer.AddSentence([]string{"мама", "мыла", "раму"},
+		[]FEATS{noun(Fem, Nom, Sing), verb(Fin, Fem, Sing), noun(Fem, Acc, Sing)}))
+	require.NoError(t, trainer.AddSentence([]string{"стали", "новый", "дом"},
+		[]FEATS{verb(Fin, 0, Plur), adj(Masc, Nom, Sing), noun(Masc, Nom, Sing)}))
+	data.Dictionary.Tagger = trainer.Tagger()
+
+	l, err := NewLemmatizer(data, WithTrigramTagger())
+	require.NoError(t, err)
+	return l
+}
+
+func bruteForceTrigram(l *Lemmatizer, sentence []Word) float64 {
+	best := -math.MaxFloat64
+	path := make([]FEATS, len(sentence))
+
+	var walk func(i int, score float64)
+	walk = func(i int, score float64) {
+		if i == len(sentence) {
+			best = max(best, score)
+			return
+		}
+		for _, f := range sentence[i].Options {
+			prev2, prev := startTag, startTag
+			if i >= 1 {
+				prev = path[i-1]
+			}
+			if i >= 2 {
+				prev2 = path[i-2]
+			}
+			path[i] = f.FEATS
+			coeff := 1.0
+			if i >= 1 {
+				coeff = transitionCoeff(prev, f.FEATS)
+			}
+			walk(i+1, score+l.trigramLogProb(prev2, prev, f.FEATS)*coeff+l.emissionLogProb(f.FEATS, sentence[i]))
+		}
+	}
+	walk(0, 0)
+
+	return best
+}
+
+func trigramPathScore(l *Lemmatizer, sentence []Word, forms []Form) float64 {
+	score := 0.0
+	for i, f := range forms {
+		prev2, prev := startTag, startTag
+		coeff := 1.0
+		if i >= 1 {
+			prev = forms[i-1].FEATS
+			coeff = transitionCoeff(prev, f.FEATS)
+		}
+		if i >= 2 {
+			prev2 = forms[i-2].FEATS
+		}
+		score += l.trigramLogProb(prev2, prev, f.FEATS)*coeff + l.emissionLogProb(f.FEATS, sentence[i])
+	}
+	return score
+}
+
+func TestTrigramViterbi(t *testing.T) {
+	l := newTrigramLemmatizer(t)
+
+	lambdas := l.base.Dictionary.Tagger.TrigramLambdas
+	assert.InDelta(t, 1.0, lambdas[0]+lambdas[1]+lambdas[2], 1e-9)
+
+	for _, text := range []string{"стали", "в новом доме", "мама мыла раму", "стали новый дом и дома стали",
+		"он читает книги в москве"} {
+		words := l.Candidates(Tokenize(text, l.keywords))
+		forms := l.Viterbi(words)
+		require.Len(t, forms, len(words))
+		assert.InDelta(t, bruteForceTrigram(l, words), trigramPathScore(l, words, forms), 1e-9, text)
+	}
+
+	assert.Equal(t, []string{"в", "новый", "дом"}, l.LemmatizeText("в новом доме"))
+	assert.Equal(t, []string{"мама", "мыть", "рама"}, l.LemmatizeText("мама мыла раму"))
+
+	_, err := NewLemmatizer(buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences), WithTrigramTagger())
+	assert.Error(t, err)
+}
+
+func TestTrigramLambdas(t *testing.T) {
+	l := newTrigramLemmatizer(t)
+	data := l.base
+	for _, lambdas := range [][3]float64{{}, {0, 0, 1}, {-0.5, 0.5, 1}, {math.NaN(), 0.5, 0.5}} {
+		data.Dictionary.Tagger.TrigramLambdas = lambdas
+		_, err := NewLemmatizer(data, WithTrigramTagger())
+		assert.Error(t, err, lambdas)
+	}
+
+	tagger := data.Dictionary.Tagger
+	tagger.TrigramCounts = map[TagPair]map[FEATS]int{{Prev2: 1, Prev: 2}: {3: 5}}
+	tagger.TagPairCounts = map[TagPair]int{{Prev2: 1, Prev: 2}: 5}
+	lambdas := deletedInterpolation(&tagger)
+	assert.GreaterOrEqual(t, lambdas[0], minInterpolationWeight/2)
+	assert.GreaterOrEqual(t, lambdas[1], minInterpolationWeight/2)
+	assert.InDelta(t, 1.0, lambdas[0]+lambdas[1]+lambdas[2], 1e-9)
+}
+
+func TestTrigramViterbiGaps(t *testing.T) {
+	l := newTrigramLemmatizer(t)
+
+	words := l.Candidates(Tokenize("мама мыла раму", l.keywords))
+	expected := l.Viterbi(words)
+	assert.Equal(t, []Form{{}, {}}, l.Viterbi([]Word{{}, {}}))
+	assert.Equal(t, append([]Form{{}}, append(expected, Form{})...), l.Viterbi(append([]Word{{}}, append(words, Word{})...)))
+}
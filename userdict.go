@@ -0,0 +1,250 @@
+package nlp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// userLemmaFlag marks Form.LemmaIdx values that index UserDictionary lemmas
+// instead of DictionaryBase.Lemmas.
+const userLemmaFlag = 1 << 31
+
+type UserEntry struct {
+	Form  string
+	Lemma string
+	FEATS FEATS
+	// Count weighs the entry in disambiguation; zero ranks it just above the
+	// most frequent dictionary reading of the same form.
+	Count uint16
+	// Override hides the dictionary readings of the form instead of merging
+	// with them. Merged entries still replace dictionary readings with equal FEATS.
+	Override bool
+}
+
+type userForms struct {
+	forms    []Form
+	override bool
+}
+
+// UserDictionary holds entries layered over DictionaryBase. Entries can be
+// added while a Lemmatizer using the dictionary is serving requests.
+type UserDictionary struct {
+	mu       sync.RWMutex
+	forms    map[string]*userForms
+	lemmas   []string
+	lemmaIdx map[string]uint32
+	lexemes  map[uint32][]FormWithFEATS
+}
+
+func NewUserDictionary() *UserDictionary {
+	return &UserDictionary{
+		forms:    map[string]*userForms{},
+		lemmaIdx: map[string]uint32{},
+		lexemes:  map[uint32][]FormWithFEATS{},
+	}
+}
+
+func (d *UserDictionary) Add(entries ...UserEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, e := range entries {
+		form, lemma := Normalize(e.Form), Normalize(e.Lemma)
+		if form == "" {
+			continue
+		}
+		if lemma == "" {
+			lemma = form
+		}
+
+		idx, ok := d.lemmaIdx[lemma]
+		if !ok {
+			idx = uint32(len(d.lemmas)) | userLemmaFlag
+			d.lemmaIdx[lemma] = idx
+			d.lemmas = append(d.lemmas, lemma)
+		}
+
+		entry := d.forms[form]
+		if entry == nil {
+			entry = &userForms{}
+			d.forms[form] = entry
+		}
+		entry.override = entry.override || e.Override
+
+		f := Form{LemmaIdx: idx, FEATS: e.FEATS, CountTotal: e.Count, CountDocs: e.Count}
+		replaced := false
+		for i := range entry.forms {
+			if entry.forms[i].LemmaIdx == idx && entry.forms[i].FEATS == e.FEATS {
+				entry.forms[i] = f
+				replaced = true
+			}
+		}
+		if !replaced {
+			entry.forms = append(entry.forms, f)
+			d.lexemes[idx] = append(d.lexemes[idx], FormWithFEATS{Text: form, FEATS: e.FEATS, CountTotal: e.Count})
+			continue
+		}
+		for i, lf := range d.lexemes[idx] {
+			if lf.Text == form && lf.FEATS == e.FEATS {
+				d.lexemes[idx][i].CountTotal = e.Count
+			}
+		}
+	}
+}
+
+func (d *UserDictionary) AddParadigm(lemma string, override bool, forms ...FormWithFEATS) {
+	entries := make([]UserEntry, len(forms))
+	for i, f := range forms {
+		entries[i] = UserEntry{Form: f.Text, Lemma: lemma, FEATS: f.FEATS, Count: f.CountTotal, Override: override}
+	}
+	d.Add(entries...)
+}
+
+func (d *UserDictionary) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.forms)
+}
+
+// overlay puts the user forms of text in front of the dictionary forms.
+func (d *UserDictionary) overlay(text string, base []Form) []Form {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entry := d.forms[text]
+	if entry == nil {
+		return base
+	}
+
+	var topTotal, topDocs uint16
+	if !entry.override {
+		for _, f := range base {
+			topTotal = max(topTotal, f.CountTotal)
+			topDocs = max(topDocs, f.CountDocs)
+		}
+	}
+
+	result := make([]Form, 0, len(entry.forms)+len(base))
+	for _, f := range entry.forms {
+		if f.CountTotal == 0 {
+			f.CountTotal = min(topTotal, math.MaxUint16-1) + 1
+			f.CountDocs = min(topDocs, math.MaxUint16-1) + 1
+		}
+		result = append(result, f)
+	}
+	if entry.override {
+		return result
+	}
+
+BASE:
+	for _, f := range base {
+		for _, u := range entry.forms {
+			if u.FEATS == f.FEATS {
+				continue BASE
+			}
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+func (d *UserDictionary) lemmaText(idx uint32) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	idx &^= userLemmaFlag
+	if int(idx) >= len(d.lemmas) {
+		return "", false
+	}
+	return d.lemmas[idx], true
+}
+
+func (d *UserDictionary) lexeme(lemma string) []FormWithFEATS {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	idx, ok := d.lemmaIdx[lemma]
+	if !ok {
+		return nil
+	}
+	return append([]FormWithFEATS(nil), d.lexemes[idx]...)
+}
+
+// ReadUserDictionary reads tab-separated entries, one per line:
+//
+//	form	lemma	[FEATS	[count	[override]]]
+//
+// FEATS use the ParseFEATS syntax, e.g. "NOUN|Case=Nom|Number=Sing". Empty
+// lines and lines starting with # are skipped.
+func ReadUserDictionary(r io.Reader) (*UserDictionary, error) {
+	d := NewUserDictionary()
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cols := strings.Split(line, "\t")
+		if len(cols) < 2 || len(cols) > 5 {
+			return nil, fmt.Errorf("line %d: expected 2 to 5 columns, got %d", lineNum, len(cols))
+		}
+
+		entry := UserEntry{Form: cols[0], Lemma: cols[1]}
+		if len(cols) > 2 {
+			feats, err := ParseFEATS(cols[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			entry.FEATS = feats
+		}
+		if len(cols) > 3 && strings.TrimSpace(cols[3]) != "" {
+			count, err := strconv.ParseUint(strings.TrimSpace(cols[3]), 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad count %q", lineNum, cols[3])
+			}
+			entry.Count = uint16(count)
+		}
+		if len(cols) > 4 {
+			switch strings.TrimSpace(cols[4]) {
+			case "override":
+				entry.Override = true
+			case "", "merge":
+			default:
+				return nil, fmt.Errorf("line %d: expected override or merge, got %q", lineNum, cols[4])
+			}
+		}
+		d.Add(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func LoadUserDictionary(path string) (*UserDictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadUserDictionary(file)
+}
+
+func WithUserDictionary(d *UserDictionary) Option {
+	return func(l *Lemmatizer) error {
+		l.user = d
+		return nil
+	}
+}
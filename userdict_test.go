@@ -0,0 +1,143 @@
+package nlp
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUserLemmatizer(t *testing.T, d *UserDictionary) *Lemmatizer {
+	t.Helper()
+
+	l, err := NewLemmatizer(buildFixtureData(fixtureForms, fixtureLinks, fixtureSentences), WithUserDictionary(d))
+	require.NoError(t, err)
+	return l
+}
+
+func propn(c Case) FEATS {
+	return FEATS(0).SetPOS(PROPN).SetGender(Masc).SetCase(c).SetNumber(Sing)
+}
+
+func TestUserDictionaryParadigm(t *testing.T) {
+	d := NewUserDictionary()
+	l := newUserLemmatizer(t, d)
+	assert.Equal(t, []string{"в", "яндексе"}, l.LemmatizeText("в Яндексе"))
+
+	d.AddParadigm("Яндекс", false,
+		FormWithFEATS{Text: "Яндекс", FEATS: propn(Nom)},
+		FormWithFEATS{Text: "Яндекса", FEATS: propn(Gen)},
+		FormWithFEATS{Text: "Яндексе", FEATS: propn(Loc)},
+	)
+
+	assert.Equal(t, []string{"в", "яндекс"}, l.LemmatizeText("в Яндексе"))
+	assert.Equal(t, "яндекс", l.LemmatizeWord("Яндекса"))
+
+	parses := l.Analyze("яндексе")
+	require.Len(t, parses, 1)
+	assert.Equal(t, Parse{Lemma: "яндекс", FEATS: propn(Loc), CountTotal: 1, CountDocs: 1, Probability: 1, Source: SourceUser}, parses[0])
+
+	assert.Equal(t, []string{"яндекса"}, l.Inflect("яндекс", FEATS(0).SetCase(Gen)))
+	assert.Len(t, l.Lexeme("яндекс"), 3)
+
+	d.Add(UserEntry{Form: "Яндекса", Lemma: "Яндекс", FEATS: propn(Gen), Count: 7})
+	lexeme := l.Lexeme("яндекс")
+	require.Len(t, lexeme, 3)
+	assert.Equal(t, FormWithFEATS{Text: "яндекса", FEATS: propn(Gen), CountTotal: 7}, lexeme[1])
+}
+
+func TestUserDictionaryMerge(t *testing.T) {
+	d := NewUserDictionary()
+	l := newUserLemmatizer(t, d)
+	require.Equal(t, "стать", l.LemmatizeWord("стали"))
+
+	d.Add(UserEntry{Form: "стали", Lemma: "сталь", FEATS: noun(Fem, Gen, Sing)})
+
+	assert.Equal(t, "сталь", l.LemmatizeWord("стали"))
+
+	parses := l.Analyze("стали")
+	require.Len(t, parses, 5)
+	assert.Equal(t, SourceUser, parses[0].Source)
+	assert.Equal(t, uint16(61), parses[0].CountTotal)
+	sources := map[ParseSource]int{}
+	for _, p := range parses {
+		sources[p.Source]++
+	}
+	assert.Equal(t, map[ParseSource]int{SourceUser: 1, SourceDictionary: 4}, sources)
+
+	// A rare merged reading competes with the dictionary ones on counts.
+	d.Add(UserEntry{Form: "мыла", Lemma: "мыл", FEATS: noun(Masc, Nom, Sing), Count: 1})
+	assert.Equal(t, "мыть", l.LemmatizeWord("мыла"))
+	d.Add(UserEntry{Form: "мыла", Lemma: "мыл", FEATS: noun(Masc, Nom, Sing), Count: 500})
+	assert.Equal(t, "мыл", l.LemmatizeWord("мыла"))
+}
+
+func TestUserDictionaryOverride(t *testing.T) {
+	d := NewUserDictionary()
+	d.Add(UserEntry{Form: "мыла", Lemma: "мыло", FEATS: noun(Neut, Gen, Sing), Override: true})
+	l := newUserLemmatizer(t, d)
+
+	parses := l.Analyze("мыла")
+	require.Len(t, parses, 1)
+	assert.Equal(t, "мыло", parses[0].Lemma)
+	assert.Equal(t, []string{"мама", "мыло", "рама"}, l.LemmatizeText("Мама мыла раму"))
+}
+
+func TestFormLemmaForeignUserForm(t *testing.T) {
+	d := NewUserDictionary()
+	d.Add(UserEntry{Form: "Яндексе", Lemma: "Яндекс", FEATS: propn(Loc)})
+	l := newUserLemmatizer(t, d)
+
+	words := l.Candidates(Tokenize("яндексе", l.keywords))
+	require.Len(t, words, 1)
+	form := words[0].Options[0]
+	assert.Equal(t, "яндекс", l.FormLemma(form, "яндексе"))
+
+	assert.Equal(t, "яндексе", newTestLemmatizer(t).FormLemma(form, "яндексе"))
+	assert.Equal(t, "яндексе", newUserLemmatizer(t, NewUserDictionary()).FormLemma(form, "яндексе"))
+}
+
+func TestReadUserDictionary(t *testing.T) {
+	d, err := ReadUserDictionary(strings.NewReader(`# product names
+Яндекс	Яндекс	PROPN|Case=Nom|Gender=Masc|Number=Sing
+Яндекса	Яндекс	PROPN|Case=Gen|Gender=Masc|Number=Sing	5
+
+мыла	мыло	NOUN|Case=Gen	10	override
+ЖКХ	жкх
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 4, d.Len())
+
+	l := newUserLemmatizer(t, d)
+	assert.Equal(t, []string{"яндекс", "мыло", "жкх"}, l.LemmatizeText("Яндекса мыла ЖКХ"))
+
+	for _, bad := range []string{"одна колонка", "a\tb\tCase=Foo", "a\tb\tNOUN\tmany", "a\tb\tNOUN\t1\tsometimes"} {
+		_, err := ReadUserDictionary(strings.NewReader(bad))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestUserDictionaryConcurrentAdd(t *testing.T) {
+	d := NewUserDictionary()
+	l := newUserLemmatizer(t, d)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			d.Add(UserEntry{Form: "слово" + strings.Repeat("а", i), Lemma: "слово", FEATS: noun(Neut, Nom, Sing)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			l.LemmatizeText("слово словоа мама мыла раму")
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, "слово", l.LemmatizeWord("словоааа"))
+}
@@ -0,0 +1,164 @@
+package nlp
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Validate checks that every index stored in the data points inside the pool it
+// refers to, so that corrupted data is reported up front rather than by a panic
+// during lookup.
+func (d *LemmatizerData) Validate() error {
+	if err := d.Dictionary.Validate(); err != nil {
+		return fmt.Errorf("dictionary: %w", err)
+	}
+	if err := d.SuffixPredictor.Validate(); err != nil {
+		return fmt.Errorf("suffix predictor: %w", err)
+	}
+	return nil
+}
+
+func (d *DictionaryBase) Validate() error {
+	texts := uint64(len(d.Texts))
+
+	for i, ft := range d.FormTexts {
+		if uint64(ft.TextStart)+uint64(ft.TextLen) > texts {
+			return fmt.Errorf("form text %d: text %d+%d out of range", i, ft.TextStart, ft.TextLen)
+		}
+		if uint64(ft.FormIdx)+uint64(ft.FormLen) > uint64(len(d.Forms)) {
+			return fmt.Errorf("form text %d: forms %d+%d out of range", i, ft.FormIdx, ft.FormLen)
+		}
+	}
+
+	for i, f := range d.Forms {
+		if int(f.LemmaIdx) >= len(d.Lemmas) {
+			return fmt.Errorf("form %d: lemma %d out of range", i, f.LemmaIdx)
+		}
+	}
+
+	for i, lemma := range d.Lemmas {
+		if uint64(lemma.TextStart)+uint64(lemma.TextLen) > texts {
+			return fmt.Errorf("lemma %d: text %d+%d out of range", i, lemma.TextStart, lemma.TextLen)
+		}
+		if uint64(lemma.LinkIdx)+uint64(lemma.LinkLen) > uint64(len(d.Links)) {
+			return fmt.Errorf("lemma %d: links %d+%d out of range", i, lemma.LinkIdx, lemma.LinkLen)
+		}
+	}
+
+	for i, link := range d.Links {
+		if int(link.FromLemmaIdx) >= len(d.Lemmas) {
+			return fmt.Errorf("link %d: lemma %d out of range", i, link.FromLemmaIdx)
+		}
+	}
+	if err := d.validateLinkCycles(); err != nil {
+		return err
+	}
+
+	for hash, idx := range d.FormTextIndex {
+		if int(idx) >= len(d.FormTexts) {
+			return fmt.Errorf("form text index %x: form text %d out of range", hash, idx)
+		}
+	}
+	for hash, chain := range d.FormTextChains {
+		for _, idx := range chain {
+			if int(idx) >= len(d.FormTexts) {
+				return fmt.Errorf("form text chain %x: form text %d out of range", hash, idx)
+			}
+		}
+	}
+	for i, e := range d.formIndex {
+		if int(e.Idx) >= len(d.FormTexts) {
+			return fmt.Errorf("form index %d: form text %d out of range", i, e.Idx)
+		}
+		if i > 0 && d.formIndex[i-1].Hash > e.Hash {
+			return fmt.Errorf("form index %d: not sorted by hash", i)
+		}
+	}
+
+	if d.lemmaFormsStart != nil {
+		if len(d.lemmaFormsStart) != len(d.Lemmas)+1 || d.lemmaFormsStart[0] != 0 ||
+			int(d.lemmaFormsStart[len(d.Lemmas)]) != len(d.lemmaForms) {
+			return fmt.Errorf("lemma forms: %d offsets for %d lemmas and %d forms",
+				len(d.lemmaFormsStart), len(d.Lemmas), len(d.lemmaForms))
+		}
+		if !slices.IsSorted(d.lemmaFormsStart) {
+			return fmt.Errorf("lemma forms: offsets not sorted")
+		}
+		for i, lf := range d.lemmaForms {
+			if int(lf.FormIdx) >= len(d.Forms) || int(lf.FormTextIdx) >= len(d.FormTexts) {
+				return fmt.Errorf("lemma form %d: form %d, form text %d out of range", i, lf.FormIdx, lf.FormTextIdx)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateLinkCycles rejects links that lead back to a lemma already on the
+// path, which followLinks would follow forever.
+func (d *DictionaryBase) validateLinkCycles() error {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	type frame struct {
+		lemma uint32
+		next  uint32
+	}
+
+	state := make([]uint8, len(d.Lemmas))
+	var stack []frame
+	for root := range d.Lemmas {
+		if state[root] != unvisited {
+			continue
+		}
+		state[root] = onPath
+		stack = append(stack[:0], frame{lemma: uint32(root)})
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			lemma := d.Lemmas[top.lemma]
+			if top.next == uint32(lemma.LinkLen) {
+				state[top.lemma] = done
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			from := d.Links[lemma.LinkIdx+top.next].FromLemmaIdx
+			top.next++
+			switch state[from] {
+			case onPath:
+				return fmt.Errorf("lemma %d: link cycle through lemma %d", top.lemma, from)
+			case unvisited:
+				state[from] = onPath
+				stack = append(stack, frame{lemma: from})
+			}
+		}
+	}
+	return nil
+}
+
+func (p *SuffixPredictorBase) Validate() error {
+	for i, rule := range p.RulePool {
+		if uint64(rule.AppendStart)+uint64(rule.AppendLen) > uint64(len(p.AppendTexts)) {
+			return fmt.Errorf("rule %d: append text %d+%d out of range", i, rule.AppendStart, rule.AppendLen)
+		}
+	}
+
+	for i, node := range p.NodePool {
+		if uint64(node.ChildrenIdx)+uint64(node.ChildrenLen) > uint64(len(p.EdgesPool)) {
+			return fmt.Errorf("node %d: edges %d+%d out of range", i, node.ChildrenIdx, node.ChildrenLen)
+		}
+		if uint64(node.RulesIdx)+uint64(node.RulesLen) > uint64(len(p.RulePool)) {
+			return fmt.Errorf("node %d: rules %d+%d out of range", i, node.RulesIdx, node.RulesLen)
+		}
+	}
+
+	for i, edge := range p.EdgesPool {
+		if edge.NodeIdx < 0 || edge.NodeIdx >= len(p.NodePool) {
+			return fmt.Errorf("edge %d: node %d out of range", i, edge.NodeIdx)
+		}
+	}
+
+	return nil
+}
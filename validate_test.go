@@ -0,0 +1,97 @@
+package nlp
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	data := binaryFixtureData()
+
+	var buf bytes.Buffer
+	require.NoError(t, data.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.NoError(t, data.SaveFile(path))
+	fromFile, err := LoadFile(path)
+	require.NoError(t, err)
+
+	expected, err := NewLemmatizer(data)
+	require.NoError(t, err)
+	for _, d := range []*LemmatizerData{loaded, fromFile} {
+		assert.Equal(t, data.Dictionary.Tagger, d.Dictionary.Tagger)
+		assert.Equal(t, data.SuffixPredictor, d.SuffixPredictor)
+
+		actual, err := NewLemmatizer(*d)
+		require.NoError(t, err)
+		for _, text := range []string{"Мама мыла раму.", "Стали читать новые книги о сталями."} {
+			assert.Equal(t, expected.AnalyzeText(text), actual.AnalyzeText(text), text)
+		}
+	}
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.bin"))
+	assert.Error(t, err)
+	_, err = Load(bytes.NewReader([]byte("NLPDATA")))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(d *LemmatizerData)
+		err     string
+	}{
+		{"form text", func(d *LemmatizerData) { d.Dictionary.FormTexts[3].TextStart = uint32(len(d.Dictionary.Texts)) }, "form text 3: text"},
+		{"form text forms", func(d *LemmatizerData) { d.Dictionary.FormTexts[0].FormLen = 255 }, "form text 0: forms"},
+		{"form", func(d *LemmatizerData) { d.Dictionary.Forms[5].LemmaIdx = 1000 }, "form 5: lemma 1000"},
+		{"lemma links", func(d *LemmatizerData) { d.Dictionary.Lemmas[2].LinkLen = 10 }, "lemma 2: links"},
+		{"link", func(d *LemmatizerData) { d.Dictionary.Links[0].FromLemmaIdx = 1000 }, "link 0: lemma 1000"},
+		{"link cycle", func(d *LemmatizerData) {
+			for i, lemma := range d.Dictionary.Lemmas {
+				if lemma.LinkLen > 0 {
+					d.Dictionary.Links[lemma.LinkIdx].FromLemmaIdx = uint32(i)
+					return
+				}
+			}
+		}, "link cycle"},
+		{"form text index", func(d *LemmatizerData) { d.Dictionary.FormTextIndex[1] = 1000 }, "form text 1000"},
+		{"node rules", func(d *LemmatizerData) { d.SuffixPredictor.NodePool[1].RulesIdx = 1 << 30 }, "node 1: rules"},
+		{"node edges", func(d *LemmatizerData) { d.SuffixPredictor.NodePool[0].ChildrenLen = 255 }, "node 0: edges"},
+		{"edge", func(d *LemmatizerData) { d.SuffixPredictor.EdgesPool[0].NodeIdx = -1 }, "edge 0: node -1"},
+		{"rule", func(d *LemmatizerData) { d.SuffixPredictor.RulePool[0].AppendLen = 255 }, "rule 0: append text"},
+	}
+
+	valid := binaryFixtureData()
+	require.NoError(t, valid.Validate())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := binaryFixtureData()
+			tt.corrupt(&data)
+			assert.ErrorContains(t, data.Validate(), tt.err)
+			assert.Error(t, data.Save(&bytes.Buffer{}))
+		})
+	}
+}
+
+func TestLoadOOVValidates(t *testing.T) {
+	oov := binaryFixtureData().SuffixPredictor
+	oov.EdgesPool[0].NodeIdx = len(oov.NodePool)
+
+	path := filepath.Join(t.TempDir(), "oov.gob")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, gob.NewEncoder(file).Encode(oov))
+	require.NoError(t, file.Close())
+
+	_, err = LoadOOV(path)
+	assert.ErrorContains(t, err, "out of range")
+}
@@ -0,0 +1,222 @@
+package nlp
+
+import (
+	"math"
+	"sync"
+)
+
+// scoreCache holds the read-only parts of GetLogScore precomputed per tag and
+// per pair of tags seen in the tagger, so decoding does no map-of-maps lookups.
+type scoreCache struct {
+	alpha float64
+
+	tags        map[FEATS]int32
+	transitions map[uint64]float64
+	unseen      []float64
+	unseenOther float64
+
+	tagCounts    map[FEATS]int
+	emitSmoothed int
+}
+
+func newScoreCache(tagger *StatisticalTagger, alpha float64) *scoreCache {
+	c := &scoreCache{
+		alpha:        alpha,
+		tags:         map[FEATS]int32{},
+		transitions:  map[uint64]float64{},
+		tagCounts:    tagger.TagTotalCounts,
+		emitSmoothed: int(alpha * float64(tagger.UniqueWords)),
+	}
+
+	addTag := func(tag FEATS) {
+		if _, ok := c.tags[tag]; !ok {
+			c.tags[tag] = int32(len(c.tags))
+		}
+	}
+	for tag := range tagger.TagTotalCounts {
+		addTag(tag & BigramMask)
+	}
+	for prev, row := range tagger.TransitionCounts {
+		addTag(prev & BigramMask)
+		for curr := range row {
+			addTag(curr & BigramMask)
+		}
+	}
+
+	transSmoothed := int(alpha * float64(tagger.UniqueTags))
+	c.unseenOther = math.Log(alpha / float64(transSmoothed))
+	c.unseen = make([]float64, len(c.tags))
+	for tag, idx := range c.tags {
+		c.unseen[idx] = math.Log(alpha / float64(tagger.TagTotalCounts[tag]+transSmoothed))
+	}
+
+	for prev, row := range tagger.TransitionCounts {
+		if prev != prev&BigramMask {
+			continue
+		}
+		denom := tagger.TagTotalCounts[prev] + transSmoothed
+		for curr, count := range row {
+			if curr != curr&BigramMask {
+				continue
+			}
+			c.transitions[tagPairKey(c.tags[prev], c.tags[curr])] = math.Log((float64(count) + alpha) / float64(denom))
+		}
+	}
+
+	return c
+}
+
+func tagPairKey(prev, curr int32) uint64 {
+	return uint64(uint32(prev))<<32 | uint64(uint32(curr))
+}
+
+func (c *scoreCache) tagIndex(tag FEATS) int32 {
+	if idx, ok := c.tags[tag&BigramMask]; ok {
+		return idx
+	}
+	return -1
+}
+
+func (c *scoreCache) transition(prev, curr int32) float64 {
+	if prev < 0 {
+		return c.unseenOther
+	}
+	if curr >= 0 {
+		if score, ok := c.transitions[tagPairKey(prev, curr)]; ok {
+			return score
+		}
+	}
+	return c.unseen[prev]
+}
+
+func (c *scoreCache) emission(tag FEATS, wordCount int) float64 {
+	denom := c.tagCounts[tag&BigramMask] + c.emitSmoothed
+	return math.Log((float64(wordCount) + c.alpha) / float64(denom))
+}
+
+type viterbiScratch struct {
+	offsets  []int
+	tags     []FEATS
+	tagIdx   []int32
+	formIdx  []int
+	emission []float64
+	logProb  []float64
+	backPtr  []int32
+}
+
+var viterbiScratchPool = sync.Pool{New: func() any { return &viterbiScratch{} }}
+
+// prepare collapses the options of every word into unique BigramMask tags, since
+// both the emission and the transition scores depend on nothing else.
+func (s *viterbiScratch) prepare(c *scoreCache, sentence []Word) {
+	s.offsets = append(s.offsets[:0], 0)
+	s.tags = s.tags[:0]
+	s.tagIdx = s.tagIdx[:0]
+	s.formIdx = s.formIdx[:0]
+	s.emission = s.emission[:0]
+
+	for _, w := range sentence {
+		start := len(s.tags)
+	OPTIONS:
+		for fi, f := range w.Options {
+			tag := f.FEATS & BigramMask
+			for k := start; k < len(s.tags); k++ {
+				if s.tags[k] == tag {
+					continue OPTIONS
+				}
+			}
+
+			wordCount := 0
+			for _, other := range w.Options[fi:] {
+				if other.FEATS&BigramMask == tag {
+					wordCount += int(other.CountTotal)
+				}
+			}
+
+			s.tags = append(s.tags, f.FEATS)
+			s.tagIdx = append(s.tagIdx, c.tagIndex(tag))
+			s.formIdx = append(s.formIdx, fi)
+			s.emission = append(s.emission, c.emission(tag, wordCount))
+		}
+		s.offsets = append(s.offsets, len(s.tags))
+	}
+
+	s.logProb = growFloats(s.logProb, len(s.tags))
+	s.backPtr = growInt32s(s.backPtr, len(s.tags))
+}
+
+func growFloats(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	return s[:n]
+}
+
+func growInt32s(s []int32, n int) []int32 {
+	if cap(s) < n {
+		return make([]int32, n)
+	}
+	return s[:n]
+}
+
+func (l *Lemmatizer) Viterbi(sentence []Word) []Form {
+	if l.trigram {
+		return l.viterbiTrigram(sentence)
+	}
+
+	n := len(sentence)
+	if n == 0 {
+		return nil
+	}
+
+	s := viterbiScratchPool.Get().(*viterbiScratch)
+	defer viterbiScratchPool.Put(s)
+	s.prepare(l.scores, sentence)
+
+	startIdx := l.scores.tagIndex(startTag)
+	for i := range n {
+		if i == 0 || s.offsets[i-1] == s.offsets[i] {
+			// A word without options breaks the chain; the next one starts anew.
+			for k := s.offsets[i]; k < s.offsets[i+1]; k++ {
+				s.logProb[k] = l.scores.transition(startIdx, s.tagIdx[k])*transitionCoeff(startTag, s.tags[k]) + s.emission[k]
+				s.backPtr[k] = -1
+			}
+			continue
+		}
+
+		for k := s.offsets[i]; k < s.offsets[i+1]; k++ {
+			best := -math.MaxFloat64
+			bestPrev := int32(s.offsets[i-1])
+			for p := s.offsets[i-1]; p < s.offsets[i]; p++ {
+				score := s.logProb[p] + (l.scores.transition(s.tagIdx[p], s.tagIdx[k])*transitionCoeff(s.tags[p], s.tags[k]) + s.emission[k])
+				if score > best {
+					best = score
+					bestPrev = int32(p)
+				}
+			}
+			s.logProb[k] = best
+			s.backPtr[k] = bestPrev
+		}
+	}
+
+	result := make([]Form, n)
+	best := int32(-1)
+	for i := n - 1; i >= 0; i-- {
+		if s.offsets[i] == s.offsets[i+1] {
+			best = -1
+			continue
+		}
+		if best < 0 {
+			best = int32(s.offsets[i])
+			for k := s.offsets[i] + 1; k < s.offsets[i+1]; k++ {
+				if s.logProb[k] > s.logProb[best] {
+					best = int32(k)
+				}
+			}
+		}
+		result[i] = sentence[i].Options[s.formIdx[best]]
+		best = s.backPtr[best]
+	}
+
+	return result
+}
@@ -0,0 +1,161 @@
+package nlp
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type referenceStep struct {
+	LogProb float64
+	BackPtr Form
+}
+
+// referenceViterbi is the original map-based decoder kept to check the array-based one.
+func referenceViterbi(l *Lemmatizer, sentence []Word) []Form {
+	n := len(sentence)
+	if n == 0 {
+		return nil
+	}
+
+	dp := make([]map[Form]referenceStep, n)
+	for i := range dp {
+		dp[i] = make(map[Form]referenceStep)
+	}
+
+	firstWord := sentence[0]
+	for _, form := range firstWord.Options {
+		score := l.GetLogScore(startTag, form.FEATS, firstWord)
+		dp[0][form] = referenceStep{LogProb: score, BackPtr: Form{FEATS: startTag}}
+	}
+
+	for i := 1; i < n; i++ {
+		currWord := sentence[i]
+		for _, currForm := range currWord.Options {
+			bestLogProb := -math.MaxFloat64
+			bestPrevForm := Form{}
+			for _, prevForm := range sentence[i-1].Options {
+				prevStep, ok := dp[i-1][prevForm]
+				if !ok {
+					continue
+				}
+				score := prevStep.LogProb + l.GetLogScore(prevForm.FEATS, currForm.FEATS, currWord)
+				if score > bestLogProb {
+					bestLogProb = score
+					bestPrevForm = prevForm
+				}
+			}
+			dp[i][currForm] = referenceStep{LogProb: bestLogProb, BackPtr: bestPrevForm}
+		}
+	}
+
+	result := make([]Form, n)
+	lastBestForm := Form{}
+	lastMaxLogProb := -math.MaxFloat64
+	for _, form := range sentence[n-1].Options {
+		if step := dp[n-1][form]; step.LogProb > lastMaxLogProb {
+			lastMaxLogProb = step.LogProb
+			lastBestForm = form
+		}
+	}
+
+	currForm := lastBestForm
+	for i := n - 1; i >= 0; i-- {
+		result[i] = currForm
+		currForm = dp[i][currForm].BackPtr
+	}
+
+	return result
+}
+
+func randomSentence(l *Lemmatizer, rnd *rand.Rand, n int) []Word {
+	var words []string
+	for range n {
+		words = append(words, fixtureForms[rnd.Intn(len(fixtureForms))].form)
+	}
+	if rnd.Intn(3) == 0 {
+		words = append(words, "абырвалг")
+	}
+	return l.Candidates(Tokenize(strings.Join(words, " "), l.keywords))
+}
+
+func TestViterbiMatchesReference(t *testing.T) {
+	l := newTestLemmatizer(t)
+	rnd := rand.New(rand.NewSource(1))
+
+	for range 500 {
+		sentence := randomSentence(l, rnd, 1+rnd.Intn(12))
+		assert.Equal(t, referenceViterbi(l, sentence), l.Viterbi(sentence))
+	}
+	assert.Nil(t, l.Viterbi(nil))
+}
+
+func TestViterbiDegenerate(t *testing.T) {
+	l := newTestLemmatizer(t)
+
+	assert.Equal(t, []Form{{}, {}}, l.Viterbi([]Word{{}, {}}))
+
+	words := l.Candidates(Tokenize("мама мыла раму", l.keywords))
+	expected := l.Viterbi(words)
+	gapped := []Word{{}, words[0], {}, words[1], words[2], {}}
+	forms := l.Viterbi(gapped)
+	assert.Equal(t, Form{}, forms[0])
+	assert.Equal(t, l.Viterbi(words[:1]), forms[1:2])
+	assert.Equal(t, Form{}, forms[2])
+	assert.Equal(t, expected[1:], forms[3:5])
+	assert.Equal(t, Form{}, forms[5])
+
+	// Without smoothing unseen transitions and emissions score -Inf or NaN.
+	l.scores = newScoreCache(&l.base.Dictionary.Tagger, 0)
+	for i := range words {
+		for j := range words[i].Options {
+			words[i].Options[j].CountTotal = 0
+		}
+	}
+	forms = l.Viterbi(words)
+	require.Len(t, forms, len(words))
+	for i, f := range forms {
+		assert.Contains(t, words[i].Options, f)
+	}
+}
+
+func TestViterbiAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items under the race detector")
+	}
+
+	l := newTestLemmatizer(t)
+	sentence := randomSentence(l, rand.New(rand.NewSource(2)), 40)
+	l.Viterbi(sentence)
+
+	allocs := testing.AllocsPerRun(100, func() { l.Viterbi(sentence) })
+	require.LessOrEqual(t, allocs, 1.0)
+}
+
+func benchmarkSentence(l *Lemmatizer) []Word {
+	return randomSentence(l, rand.New(rand.NewSource(3)), 50)
+}
+
+func BenchmarkViterbi(b *testing.B) {
+	l := newTestLemmatizer(b)
+	sentence := benchmarkSentence(l)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Viterbi(sentence)
+	}
+}
+
+func BenchmarkViterbiReference(b *testing.B) {
+	l := newTestLemmatizer(b)
+	sentence := benchmarkSentence(l)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		referenceViterbi(l, sentence)
+	}
+}